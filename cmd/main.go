@@ -17,6 +17,11 @@
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key for service-to-service access.
 package main
 
 import (
@@ -25,6 +30,8 @@ import (
 	"log"
 
 	"go-gin-gorm-backend/handler"
+	"go-gin-gorm-backend/mailer"
+	"go-gin-gorm-backend/oidc"
 	"go-gin-gorm-backend/repository"
 	"go-gin-gorm-backend/router"
 	"go-gin-gorm-backend/service"
@@ -38,6 +45,12 @@ func main() {
 		log.Println("No .env file found or error loading .env file")
 	}
 	fmt.Println("Server starting...")
+
+	// Initialize password hashing and policy before any password is hashed
+	if err := config.InitPasswords(); err != nil {
+		log.Fatalf("Could not initialize password hashing: %v", err)
+	}
+
 	dbConfig := config.LoadDBConfig()
 	db, err := config.ConnectDB(dbConfig)
 	if err != nil {
@@ -49,35 +62,120 @@ func main() {
 		log.Fatalf("Migration failed: %v", err)
 	}
 
+	// Seed the default organization
+	config.SeedDefaultOrganization(db)
+
 	// Seed initial topics
 	config.SeedTopics(db)
 
 	// Seed initial topic details
 	config.SeedTopicDetails(db)
 
+	// Seed roles and permissions
+	config.SeedRolesAndPermissions(db)
+
 	// Seed admin user
 	config.SeedAdminUser(db)
 
 	// Initialize JWT
-	config.InitJWT()
+	if err := config.InitJWT(); err != nil {
+		log.Fatalf("Could not initialize JWT keys: %v", err)
+	}
+
+	// Initialize mailer
+	mailConfig := config.LoadMailConfig()
+	mail, err := mailer.NewMailer(mailConfig)
+	if err != nil {
+		log.Fatalf("Could not initialize mailer: %v", err)
+	}
 
 	// Initialize repositories
 	topicRepo := repository.NewTopicRepository(db)
 	topicDetailRepo := repository.NewTopicDetailRepository(db)
+	topicACLRepo := repository.NewTopicACLRepository(db)
 	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	userIdentityRepo := repository.NewUserIdentityRepository(db)
+	oidcAuthRequestRepo := repository.NewOIDCAuthRequestRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	organizationRepo := repository.NewOrganizationRepository(db)
+	oauthClientRepo := repository.NewOAuthClientRepository(db)
+	personalDataRepo := repository.NewPersonalDataRepository(db)
+	impersonationRepo := repository.NewImpersonationRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	securityEventRepo := repository.NewSecurityEventRepository(db)
+
+	// Teams are resolved on every authenticated request, so they are cached like the revocation timestamps
+	teamConfig := config.LoadTeamConfig()
+	teamRepo := repository.NewTeamRepository(db)
+	if teamConfig.CacheTTL > 0 {
+		teamRepo = repository.NewCachedTeamRepository(teamRepo, teamConfig.CacheTTL)
+	}
+
+	// Token revocation store: "memory" for single-instance setups, database otherwise.
+	// Per-user revocations are checked on every request, so the database store is fronted by a short-lived cache.
+	revocationConfig := config.LoadTokenRevocationConfig()
+	var revocationRepo repository.TokenRevocationRepository
+	if revocationConfig.Store == config.TokenRevocationStoreMemory {
+		revocationRepo = repository.NewMemoryTokenRevocationRepository()
+	} else {
+		revocationRepo = repository.NewTokenRevocationRepository(db)
+		if revocationConfig.CacheTTL > 0 {
+			revocationRepo = repository.NewCachedTokenRevocationRepository(revocationRepo, revocationConfig.CacheTTL)
+		}
+	}
 
 	// Initialize services
-	topicService := service.NewTopicService(topicRepo)
-	topicDetailService := service.NewTopicDetailService(topicDetailRepo)
-	userService := service.NewUserService(userRepo)
+	topicService := service.NewTopicService(topicRepo, teamRepo, topicACLRepo, userRepo)
+	topicDetailService := service.NewTopicDetailService(topicDetailRepo, topicRepo, topicACLRepo)
+	securityEventService := service.NewSecurityEventService(securityEventRepo)
+	tokenService := service.NewTokenService(userRepo, refreshTokenRepo, revocationRepo, sessionRepo, securityEventService)
+	loginThrottleService := service.NewLoginThrottleService(loginThrottleRepo, config.LoadLoginThrottleConfig())
+	twoFactorService := service.NewTwoFactorService(userRepo, recoveryCodeRepo, tokenService, loginThrottleService, securityEventService, config.LoadTwoFactorConfig())
+	userService := service.NewUserService(userRepo, roleRepo, organizationRepo, tokenService, loginThrottleService, twoFactorService, securityEventService)
+	roleService := service.NewRoleService(roleRepo, tokenService)
+	passwordResetService := service.NewPasswordResetService(userRepo, passwordResetRepo, tokenService, securityEventService, mail, mailConfig.LinkBaseURL)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, roleRepo)
+	registrationService := service.NewRegistrationService(config.LoadRegistrationConfig(), userRepo, roleRepo, organizationRepo, emailVerificationRepo, mail, mailConfig.LinkBaseURL)
+	profileService := service.NewProfileService(userRepo, registrationService)
+	privacyService := service.NewPrivacyService(userRepo, personalDataRepo, tokenService, loginThrottleService)
+	sessionService := service.NewSessionService(sessionRepo, userRepo, tokenService)
+	organizationService := service.NewOrganizationService(organizationRepo)
+	oauthService := service.NewOAuthService(config.LoadOAuthConfig(), oauthClientRepo, roleRepo, tokenService)
+	impersonationService := service.NewImpersonationService(config.LoadImpersonationConfig(), impersonationRepo, userRepo, tokenService)
+	teamService := service.NewTeamService(teamRepo, roleRepo, userRepo)
+	invitationService := service.NewInvitationService(config.LoadInvitationConfig(), invitationRepo, userRepo, roleRepo, teamRepo, organizationRepo, mail, mailConfig.LinkBaseURL)
+	oidcConfig := config.LoadOIDCConfig()
+	oidcService := service.NewOIDCService(oidcConfig, oidc.NewProvider(oidcConfig), oidcAuthRequestRepo, userIdentityRepo, userRepo, roleRepo, organizationRepo, tokenService, twoFactorService, securityEventService)
 
 	// Initialize handlers
 	topicHandler := handler.NewTopicHandler(topicService)
 	topicDetailHandler := handler.NewTopicDetailHandler(topicDetailService)
-	authHandler := handler.NewAuthHandler(userService)
+	authHandler := handler.NewAuthHandler(userService, tokenService, passwordResetService)
+	registrationHandler := handler.NewRegistrationHandler(registrationService)
+	profileHandler := handler.NewProfileHandler(profileService)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+	oidcHandler := handler.NewOIDCHandler(oidcService, oidcConfig.RedirectURL)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	userHandler := handler.NewUserHandler(userService, tokenService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	organizationHandler := handler.NewOrganizationHandler(organizationService)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService)
+	invitationHandler := handler.NewInvitationHandler(invitationService)
+	teamHandler := handler.NewTeamHandler(teamService)
+	securityEventHandler := handler.NewSecurityEventHandler(securityEventService)
 
 	// Setup router
-	r := router.SetupRouter(topicHandler, topicDetailHandler, authHandler)
+	r := router.SetupRouter(topicHandler, topicDetailHandler, authHandler, registrationHandler, profileHandler, privacyHandler, oidcHandler, twoFactorHandler, userHandler, roleHandler, apiKeyHandler, sessionHandler, organizationHandler, oauthHandler, impersonationHandler, invitationHandler, teamHandler, securityEventHandler, tokenService, apiKeyService, teamService, userService)
 
 	// Start server
 	r.Run()
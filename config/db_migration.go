@@ -7,13 +7,33 @@ import (
 )
 
 func MigrateDB(db *gorm.DB) error {
-	// Drop existing tables if they exist (for SQL Server compatibility)
-	db.Migrator().DropTable(&model.TopicDetail{})
-	db.Migrator().DropTable(&model.Topic{})
-	db.Migrator().DropTable(&model.User{})
-
-	// Auto migrate the basic structure
-	if err := db.AutoMigrate(&model.User{}, &model.Topic{}, &model.TopicDetail{}); err != nil {
+	// Tables are only migrated, never dropped. Users and topics keep their IDs across restarts,
+	// so token revocations, API keys, sessions and the other rows referring to them stay valid.
+	if err := db.AutoMigrate(
+		&model.Organization{},
+		&model.Permission{},
+		&model.Role{},
+		&model.User{},
+		&model.Team{},
+		&model.RefreshToken{},
+		&model.RevokedToken{},
+		&model.UserTokenRevocation{},
+		&model.PasswordResetToken{},
+		&model.LoginThrottle{},
+		&model.APIKey{},
+		&model.UserIdentity{},
+		&model.OIDCAuthRequest{},
+		&model.RecoveryCode{},
+		&model.Session{},
+		&model.EmailVerificationToken{},
+		&model.OAuthClient{},
+		&model.Impersonation{},
+		&model.Invitation{},
+		&model.SecurityEvent{},
+		&model.Topic{},
+		&model.TopicDetail{},
+		&model.TopicACLEntry{},
+	); err != nil {
 		return err
 	}
 
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"time"
+)
+
+type ImpersonationConfig struct {
+	TokenTTL time.Duration // lifetime of impersonation tokens
+}
+
+func LoadImpersonationConfig() *ImpersonationConfig {
+	cfg := &ImpersonationConfig{
+		TokenTTL: getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
+	}
+
+	// Ending an impersonation revokes its session for as long as access tokens live,
+	// so impersonation tokens must not outlive them
+	if cfg.TokenTTL > AccessTokenTTL {
+		cfg.TokenTTL = AccessTokenTTL
+	}
+	return cfg
+}
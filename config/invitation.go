@@ -0,0 +1,15 @@
+package config
+
+import (
+	"time"
+)
+
+type InvitationConfig struct {
+	TTL time.Duration // lifetime of invitation links
+}
+
+func LoadInvitationConfig() *InvitationConfig {
+	return &InvitationConfig{
+		TTL: getEnvDuration("INVITATION_TTL", 7*24*time.Hour),
+	}
+}
@@ -2,71 +2,261 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/utils"
+
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtSecret []byte
+// Token lifetimes
+const (
+	AccessTokenTTL  = 24 * time.Hour
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+var (
+	jwtKeys       map[string]*jwtKey
+	jwtKeyOrder   []string
+	jwtSigningKey *jwtKey
+)
+
+// InitJWT initializes JWT signing and verification keys.
+//
+// JWT_PRIVATE_KEYS lists RSA or Ed25519 private keys as "kid=path.pem,kid=path.pem" and
+// JWT_SIGNING_KEY_ID selects the one used for new tokens (the first one by default).
+// JWT_PUBLIC_KEYS lists retired public keys that are still accepted for verification.
+// JWT_SECRET configures an HS256 key; it signs only when no private key is configured.
+func InitJWT() error {
+	jwtKeys = make(map[string]*jwtKey)
+	jwtKeyOrder = nil
+	jwtSigningKey = nil
 
-// InitJWT initializes JWT configuration
-func InitJWT() {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key-change-in-production"
+	privateKeys, err := parseKeyList(os.Getenv("JWT_PRIVATE_KEYS"))
+	if err != nil {
+		return err
 	}
-	jwtSecret = []byte(secret)
+	for _, entry := range privateKeys {
+		key, err := loadPrivateKey(entry[0], entry[1])
+		if err != nil {
+			return err
+		}
+		if err := addJWTKey(key); err != nil {
+			return err
+		}
+	}
+
+	publicKeys, err := parseKeyList(os.Getenv("JWT_PUBLIC_KEYS"))
+	if err != nil {
+		return err
+	}
+	for _, entry := range publicKeys {
+		key, err := loadPublicKey(entry[0], entry[1])
+		if err != nil {
+			return err
+		}
+		if err := addJWTKey(key); err != nil {
+			return err
+		}
+	}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		kid := os.Getenv("JWT_SECRET_KEY_ID")
+		if kid == "" {
+			kid = "hs256"
+		}
+		if err := addJWTKey(&jwtKey{id: kid, method: jwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)}); err != nil {
+			return err
+		}
+	}
+
+	signingKeyID := os.Getenv("JWT_SIGNING_KEY_ID")
+	for _, kid := range jwtKeyOrder {
+		key := jwtKeys[kid]
+		if key.signKey != nil && (signingKeyID == "" || signingKeyID == kid) {
+			jwtSigningKey = key
+			break
+		}
+	}
+
+	if jwtSigningKey == nil {
+		if signingKeyID != "" {
+			return fmt.Errorf("JWT_SIGNING_KEY_ID %q does not match a configured private key", signingKeyID)
+		}
+		return errors.New("no JWT signing key configured: set JWT_PRIVATE_KEYS or JWT_SECRET")
+	}
+
+	return nil
+}
+
+// signToken signs claims with the current signing key and records its kid in the token header
+func signToken(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwtSigningKey.method, claims)
+	token.Header["kid"] = jwtSigningKey.id
+	return token.SignedString(jwtSigningKey.signKey)
+}
+
+// verificationKey resolves the key for a token from its kid header
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := jwtKeys[kid]
+	if !ok && kid == "" {
+		// Tokens issued before key IDs were introduced were signed with the HS256 secret
+		for _, k := range jwtKeys {
+			if k.method == jwt.SigningMethodHS256 {
+				key, ok = k, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.verifyKey, nil
 }
 
-// GenerateToken generates a new JWT token
-func GenerateToken(user *model.User) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour) // 24 hours
+// GenerateToken generates a new JWT token bound to a session (refresh token family).
+// issuedAt is normally the current time; it is only later when the token has to outrank a revocation.
+func GenerateToken(user *model.User, sessionID string, issuedAt time.Time) (string, error) {
+	expirationTime := issuedAt.Add(AccessTokenTTL)
+
+	tokenID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
 
 	claims := &model.Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:             user.ID,
+		Username:           user.Username,
+		Roles:              user.RoleNames(),
+		Permissions:        user.EffectivePermissions(),
+		TenantID:           user.TenantID,
+		SessionID:          sessionID,
+		MustChangePassword: user.MustChangePassword,
+		TwoFactorSetup:     user.RequiresTwoFactor() && !user.TwoFactorEnabled,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return signToken(claims)
+}
+
+// GenerateImpersonationToken generates a short-lived access token that acts as user on behalf of actor.
+// It carries exactly the claims a token of the user would, plus the act claim naming the actor.
+func GenerateImpersonationToken(user, actor *model.User, sessionID string, ttl time.Duration) (string, time.Time, error) {
+	expirationTime := time.Now().Add(ttl)
+
+	tokenID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	claims := &model.Claims{
+		UserID:             user.ID,
+		Username:           user.Username,
+		Roles:              user.RoleNames(),
+		Permissions:        user.EffectivePermissions(),
+		TenantID:           user.TenantID,
+		SessionID:          sessionID,
+		MustChangePassword: user.MustChangePassword,
+		TwoFactorSetup:     user.RequiresTwoFactor() && !user.TwoFactorEnabled,
+		Act:                &model.Actor{UserID: actor.ID, Username: actor.Username},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token, err := signToken(claims)
+	return token, expirationTime, err
+}
+
+// GenerateClientToken generates an access token for an OAuth client (client credentials grant).
+// The granted scopes become the permissions of the token, so routes enforce them like user permissions.
+func GenerateClientToken(client *model.OAuthClient, scopes []string, ttl time.Duration) (string, time.Time, error) {
+	expirationTime := time.Now().Add(ttl)
+
+	tokenID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	claims := &model.Claims{
+		Username:    client.ClientID,
+		Permissions: scopes,
+		TenantID:    client.TenantID,
+		ClientID:    client.ClientID,
+		Scope:       strings.Join(scopes, " "),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   client.ClientID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	token, err := signToken(claims)
+	return token, expirationTime, err
 }
 
-// GenerateRefreshToken generates a new refresh token
-func GenerateRefreshToken(user *model.User) (string, error) {
-	expirationTime := time.Now().Add(7 * 24 * time.Hour) // 7 days
+// GenerateChallengeToken generates a short-lived token proving the password step of a two-step login.
+// It carries no roles or permissions and is rejected wherever an access token is expected.
+func GenerateChallengeToken(user *model.User, ttl time.Duration) (string, time.Time, error) {
+	expirationTime := time.Now().Add(ttl)
+
+	tokenID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
 
 	claims := &model.Claims{
 		UserID:   user.ID,
 		Username: user.Username,
-		Role:     user.Role,
+		Purpose:  model.TokenPurposeTwoFactorChallenge,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	token, err := signToken(claims)
+	return token, expirationTime, err
+}
+
+// GenerateRefreshToken generates a new opaque refresh token and its expiration time.
+// Refresh tokens are random strings rather than JWTs so they can never be used as access tokens.
+func GenerateRefreshToken() (string, time.Time, error) {
+	expirationTime := time.Now().Add(RefreshTokenTTL)
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expirationTime, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString string) (*model.Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &model.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &model.Claims{}, verificationKey,
+		jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}))
 
 	if err != nil {
 		return nil, err
@@ -78,15 +268,3 @@ func ValidateToken(tokenString string) (*model.Claims, error) {
 
 	return nil, errors.New("invalid token")
 }
-
-// HashPassword hashes a password using bcrypt
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
-}
-
-// CheckPassword checks if a password matches its hash
-func CheckPassword(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
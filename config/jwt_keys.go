@@ -0,0 +1,157 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"go-gin-gorm-backend/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtKey is a key used to sign or verify tokens. signKey is nil for verification-only (retired) keys.
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// parseKeyList parses a "kid=path,kid=path" list
+func parseKeyList(value string) ([][2]string, error) {
+	var entries [][2]string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(item, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid key entry %q, expected kid=path", item)
+		}
+		entries = append(entries, [2]string{strings.TrimSpace(kid), strings.TrimSpace(path)})
+	}
+	return entries, nil
+}
+
+func readPEMBlock(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", path)
+	}
+	return block, nil
+}
+
+// loadPrivateKey loads an RSA or Ed25519 private key from a PEM file
+func loadPrivateKey(kid, path string) (*jwtKey, error) {
+	block, err := readPEMBlock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %v", path, err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key %s must be at least 2048 bits", path)
+		}
+		return &jwtKey{id: kid, method: jwt.SigningMethodRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &jwtKey{id: kid, method: jwt.SigningMethodEdDSA, signKey: k, verifyKey: k.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type in %s, use RSA or Ed25519", path)
+	}
+}
+
+// loadPublicKey loads an RSA or Ed25519 public key from a PEM file
+func loadPublicKey(kid, path string) (*jwtKey, error) {
+	block, err := readPEMBlock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key interface{}
+	if block.Type == "RSA PUBLIC KEY" {
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %v", path, err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return &jwtKey{id: kid, method: jwt.SigningMethodRS256, verifyKey: k}, nil
+	case ed25519.PublicKey:
+		return &jwtKey{id: kid, method: jwt.SigningMethodEdDSA, verifyKey: k}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type in %s, use RSA or Ed25519", path)
+	}
+}
+
+// toJWK converts the public part of an asymmetric key to a JWK. HMAC keys are never published.
+func (k *jwtKey) toJWK() (model.JWK, bool) {
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return model.JWK{
+			Kty: "RSA",
+			Kid: k.id,
+			Use: "sig",
+			Alg: k.method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return model.JWK{
+			Kty: "OKP",
+			Kid: k.id,
+			Use: "sig",
+			Alg: k.method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, true
+	default:
+		return model.JWK{}, false
+	}
+}
+
+// PublicJWKS returns the public keys that verify our tokens so other services can validate them
+func PublicJWKS() model.JWKS {
+	jwks := model.JWKS{Keys: []model.JWK{}}
+	for _, kid := range jwtKeyOrder {
+		if jwk, ok := jwtKeys[kid].toJWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+func addJWTKey(key *jwtKey) error {
+	if _, exists := jwtKeys[key.id]; exists {
+		return fmt.Errorf("duplicate JWT key id %q", key.id)
+	}
+	jwtKeys[key.id] = key
+	jwtKeyOrder = append(jwtKeyOrder, key.id)
+	return nil
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeTestKey writes a new Ed25519 key pair as PEM files and returns the private and public key paths
+func writeTestKey(t *testing.T, name string) (string, string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+// setJWTEnv configures the JWT keys for one step of a test
+func setJWTEnv(t *testing.T, privateKeys, signingKeyID, publicKeys, secret string) {
+	t.Helper()
+	t.Setenv("JWT_PRIVATE_KEYS", privateKeys)
+	t.Setenv("JWT_SIGNING_KEY_ID", signingKeyID)
+	t.Setenv("JWT_PUBLIC_KEYS", publicKeys)
+	t.Setenv("JWT_SECRET", secret)
+	t.Setenv("JWT_SECRET_KEY_ID", "")
+	if err := InitJWT(); err != nil {
+		t.Fatalf("InitJWT() error = %v", err)
+	}
+}
+
+// tokenKeyID returns the kid header of a token
+func tokenKeyID(t *testing.T, tokenString string) string {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &model.Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
+
+// jwksKeyIDs returns the kids published in the JWKS
+func jwksKeyIDs() []string {
+	var kids []string
+	for _, key := range PublicJWKS().Keys {
+		kids = append(kids, key.Kid)
+	}
+	return kids
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	oldPrivate, oldPublic := writeTestKey(t, "2026-01")
+	newPrivate, _ := writeTestKey(t, "2026-06")
+	user := &model.User{ID: 2, Username: "somchai"}
+
+	// Before the rotation only the old key is configured
+	setJWTEnv(t, "2026-01="+oldPrivate, "", "", "")
+	oldToken, err := GenerateToken(user, "session-1", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKeyID(t, oldToken); kid != "2026-01" {
+		t.Fatalf("kid = %q, want 2026-01", kid)
+	}
+
+	steps := []struct {
+		name         string
+		privateKeys  string
+		signingKeyID string
+		publicKeys   string
+		wantKid      string   // kid of newly issued tokens
+		wantJWKS     []string // kids published in the JWKS
+		oldTokenOK   bool
+	}{
+		{
+			name:         "new key added and signing",
+			privateKeys:  "2026-01=" + oldPrivate + ",2026-06=" + newPrivate,
+			signingKeyID: "2026-06",
+			wantKid:      "2026-06",
+			wantJWKS:     []string{"2026-01", "2026-06"},
+			oldTokenOK:   true,
+		},
+		{
+			name:        "old key retired to a public key",
+			privateKeys: "2026-06=" + newPrivate,
+			publicKeys:  "2026-01=" + oldPublic,
+			wantKid:     "2026-06",
+			wantJWKS:    []string{"2026-06", "2026-01"},
+			oldTokenOK:  true,
+		},
+		{
+			name:        "old key removed",
+			privateKeys: "2026-06=" + newPrivate,
+			wantKid:     "2026-06",
+			wantJWKS:    []string{"2026-06"},
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			setJWTEnv(t, step.privateKeys, step.signingKeyID, step.publicKeys, "")
+
+			newToken, err := GenerateToken(user, "session-2", time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kid := tokenKeyID(t, newToken); kid != step.wantKid {
+				t.Errorf("kid = %q, want %q", kid, step.wantKid)
+			}
+			if _, err := ValidateToken(newToken); err != nil {
+				t.Errorf("ValidateToken(new token) error = %v", err)
+			}
+
+			_, err = ValidateToken(oldToken)
+			if step.oldTokenOK && err != nil {
+				t.Errorf("ValidateToken(old token) error = %v", err)
+			}
+			if !step.oldTokenOK && err == nil {
+				t.Error("ValidateToken(old token) succeeded after its key was removed")
+			}
+
+			kids := jwksKeyIDs()
+			if len(kids) != len(step.wantJWKS) {
+				t.Fatalf("JWKS kids = %v, want %v", kids, step.wantJWKS)
+			}
+			for i := range kids {
+				if kids[i] != step.wantJWKS[i] {
+					t.Fatalf("JWKS kids = %v, want %v", kids, step.wantJWKS)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTokenKeyID(t *testing.T) {
+	keyPath, _ := writeTestKey(t, "2026-06")
+	otherKeyPath, _ := writeTestKey(t, "other")
+	setJWTEnv(t, "2026-06="+keyPath, "", "", "shared-secret")
+
+	otherKey, err := loadPrivateKey("other", otherKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, &model.Claims{
+			UserID:   2,
+			Username: "somchai",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+			},
+		})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "configured key", token: sign(jwt.SigningMethodEdDSA, "2026-06", jwtKeys["2026-06"].signKey)},
+		{name: "legacy HS256 token without kid", token: sign(jwt.SigningMethodHS256, "", []byte("shared-secret"))},
+		{name: "unknown kid", token: sign(jwt.SigningMethodEdDSA, "2025-01", otherKey.signKey), wantErr: true},
+		{name: "known kid signed by another key", token: sign(jwt.SigningMethodEdDSA, "2026-06", otherKey.signKey), wantErr: true},
+		{name: "known kid with another algorithm", token: sign(jwt.SigningMethodHS256, "2026-06", []byte("shared-secret")), wantErr: true},
+		{name: "asymmetric token without kid", token: sign(jwt.SigningMethodEdDSA, "", jwtKeys["2026-06"].signKey), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The HS256 secret verifies tokens but is never published
+	if kids := jwksKeyIDs(); len(kids) != 1 || kids[0] != "2026-06" {
+		t.Errorf("JWKS kids = %v, want [2026-06]", kids)
+	}
+}
+
+func TestInitJWTRejectsRetiredSigningKey(t *testing.T) {
+	keyPath, _ := writeTestKey(t, "2026-06")
+	_, oldPublic := writeTestKey(t, "2026-01")
+	t.Setenv("JWT_PRIVATE_KEYS", "2026-06="+keyPath)
+	t.Setenv("JWT_PUBLIC_KEYS", "2026-01="+oldPublic)
+	t.Setenv("JWT_SIGNING_KEY_ID", "2026-01")
+	t.Setenv("JWT_SECRET", "")
+
+	if err := InitJWT(); err == nil {
+		t.Fatal("InitJWT() accepted a public key as the signing key")
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+type LoginThrottleConfig struct {
+	MaxAttemptsPerUser int           // failed attempts per username before the account is locked
+	MaxAttemptsPerIP   int           // failed attempts per client IP before the IP is locked
+	LockoutDuration    time.Duration // first lockout, doubled for every further failure
+	MaxLockoutDuration time.Duration
+	AttemptWindow      time.Duration // failures older than this are forgotten
+}
+
+func LoadLoginThrottleConfig() *LoginThrottleConfig {
+	return &LoginThrottleConfig{
+		MaxAttemptsPerUser: getEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+		MaxAttemptsPerIP:   getEnvInt("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+		LockoutDuration:    getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		MaxLockoutDuration: getEnvDuration("LOGIN_MAX_LOCKOUT_DURATION", 24*time.Hour),
+		AttemptWindow:      getEnvDuration("LOGIN_ATTEMPT_WINDOW", time.Hour),
+	}
+}
+
+// getEnvInt reads an integer environment variable, falling back to def when unset or invalid
+func getEnvInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using %d", value, key, def)
+		return def
+	}
+	return n
+}
+
+// getEnvDuration reads a duration environment variable such as "15m", falling back to def when unset or invalid
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using %s", value, key, def)
+		return def
+	}
+	return d
+}
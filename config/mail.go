@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+)
+
+type MailConfig struct {
+	Driver       string // "smtp", "file" or "stdout"
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	FilePath     string // used by the "file" driver
+	LinkBaseURL  string // base URL of the frontend used to build links in mails
+}
+
+func LoadMailConfig() *MailConfig {
+	driver := os.Getenv("MAIL_DRIVER")
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpPort := os.Getenv("SMTP_PORT")
+	smtpUsername := os.Getenv("SMTP_USERNAME")
+	smtpPassword := os.Getenv("SMTP_PASSWORD")
+	from := os.Getenv("MAIL_FROM")
+	filePath := os.Getenv("MAIL_FILE_PATH")
+	linkBaseURL := os.Getenv("FRONTEND_URL")
+
+	if driver == "" {
+		driver = "stdout"
+	}
+	if smtpHost == "" {
+		smtpHost = "localhost"
+	}
+	if smtpPort == "" {
+		smtpPort = "25"
+	}
+	if from == "" {
+		from = "no-reply@example.com"
+	}
+	if filePath == "" {
+		filePath = "mail.log"
+	}
+	if linkBaseURL == "" {
+		linkBaseURL = "http://localhost:3000"
+	}
+
+	return &MailConfig{
+		Driver:       driver,
+		SMTPHost:     smtpHost,
+		SMTPPort:     smtpPort,
+		SMTPUsername: smtpUsername,
+		SMTPPassword: smtpPassword,
+		From:         from,
+		FilePath:     filePath,
+		LinkBaseURL:  linkBaseURL,
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"time"
+)
+
+type OAuthConfig struct {
+	TokenTTL time.Duration // lifetime of access tokens issued to OAuth clients
+}
+
+func LoadOAuthConfig() *OAuthConfig {
+	return &OAuthConfig{
+		TokenTTL: getEnvDuration("OAUTH_TOKEN_TTL", time.Hour),
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+type OIDCConfig struct {
+	Enabled       bool
+	IssuerURL     string // discovery is loaded from <issuer>/.well-known/openid-configuration
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string   // must point at /auth/oidc/callback
+	Scopes        []string // "openid" is always requested
+	AutoProvision bool     // create local users for unknown identities
+	DefaultRole   string   // role given to auto-provisioned users
+	Organization  string   // slug of the organization auto-provisioned users join
+	LinkByEmail   bool     // link an identity to an existing regular user of Organization with the same verified email
+}
+
+func LoadOIDCConfig() *OIDCConfig {
+	issuerURL := strings.TrimSuffix(os.Getenv("OIDC_ISSUER_URL"), "/")
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	clientSecret := os.Getenv("OIDC_CLIENT_SECRET")
+	redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+	scopes := strings.Fields(os.Getenv("OIDC_SCOPES"))
+	defaultRole := os.Getenv("OIDC_DEFAULT_ROLE")
+	organization := os.Getenv("OIDC_ORGANIZATION")
+
+	if redirectURL == "" {
+		redirectURL = "http://localhost:8080/auth/oidc/callback"
+	}
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	if defaultRole == "" {
+		defaultRole = "user"
+	}
+	if organization == "" {
+		organization = "default"
+	}
+
+	return &OIDCConfig{
+		Enabled:       issuerURL != "" && clientID != "",
+		IssuerURL:     issuerURL,
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   redirectURL,
+		Scopes:        scopes,
+		AutoProvision: os.Getenv("OIDC_AUTO_PROVISION") != "false",
+		DefaultRole:   defaultRole,
+		Organization:  organization,
+		LinkByEmail:   os.Getenv("OIDC_LINK_BY_EMAIL") == "true",
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"go-gin-gorm-backend/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// SeedDefaultOrganization creates the organization that seeded data and the admin user belong to
+func SeedDefaultOrganization(db *gorm.DB) {
+	var count int64
+	db.Model(&model.Organization{}).Where("slug = ?", model.DefaultOrganizationSlug).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	organization := model.Organization{Name: "Default", Slug: model.DefaultOrganizationSlug}
+	if err := db.Create(&organization).Error; err != nil {
+		log.Printf("Error creating default organization: %v", err)
+	}
+}
+
+// defaultOrganizationID returns the ID of the default organization, or 0 when it has not been seeded
+func defaultOrganizationID(db *gorm.DB) uint {
+	var organization model.Organization
+	if err := db.Where("slug = ?", model.DefaultOrganizationSlug).First(&organization).Error; err != nil {
+		log.Printf("Could not find default organization: %v", err)
+		return 0
+	}
+	return organization.ID
+}
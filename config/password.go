@@ -0,0 +1,253 @@
+package config
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+	// bcryptMaxLength is the number of bytes bcrypt looks at, longer passwords are rejected
+	bcryptMaxLength = 72
+)
+
+type PasswordConfig struct {
+	Algorithm         string // used for new hashes; hashes of the other algorithm are still accepted
+	BcryptCost        int
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	MinLength         int    // characters
+	MaxLength         int    // characters; bcrypt additionally limits passwords to 72 bytes
+	BreachedListFile  string // passwords or SHA-1 hashes (the HIBP "HASH:count" format), one per line
+}
+
+var (
+	// passwordConfig holds the defaults until InitPasswords loads the configuration
+	passwordConfig    = &PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.DefaultCost, MinLength: 8, MaxLength: 128}
+	breachedPasswords map[string]struct{}
+	// dummyPasswordHash is a hash of a random password with the configured parameters, see SimulatePasswordCheck
+	dummyPasswordHash string
+)
+
+func LoadPasswordConfig() *PasswordConfig {
+	algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM")
+	if algorithm == "" {
+		algorithm = PasswordAlgorithmArgon2id
+	}
+
+	// Defaults follow the OWASP password storage recommendations
+	cfg := &PasswordConfig{
+		Algorithm:         algorithm,
+		BcryptCost:        getEnvInt("PASSWORD_BCRYPT_COST", 12),
+		Argon2Memory:      uint32(getEnvInt("PASSWORD_ARGON2_MEMORY", 19*1024)),
+		Argon2Iterations:  uint32(getEnvInt("PASSWORD_ARGON2_ITERATIONS", 2)),
+		Argon2Parallelism: uint8(getEnvInt("PASSWORD_ARGON2_PARALLELISM", 1)),
+		MinLength:         getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:         getEnvInt("PASSWORD_MAX_LENGTH", 128),
+		BreachedListFile:  os.Getenv("PASSWORD_BREACHED_LIST_FILE"),
+	}
+	return cfg
+}
+
+// InitPasswords loads the password hashing configuration and policy, including the breached password list
+func InitPasswords() error {
+	cfg := LoadPasswordConfig()
+
+	switch cfg.Algorithm {
+	case PasswordAlgorithmArgon2id:
+		if cfg.Argon2Memory < 8*uint32(cfg.Argon2Parallelism) || cfg.Argon2Iterations < 1 || cfg.Argon2Parallelism < 1 {
+			return errors.New("invalid argon2id parameters")
+		}
+	case PasswordAlgorithmBcrypt:
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("unknown password hash algorithm %q", cfg.Algorithm)
+	}
+
+	breached := make(map[string]struct{})
+	if cfg.BreachedListFile != "" {
+		file, err := os.Open(cfg.BreachedListFile)
+		if err != nil {
+			return fmt.Errorf("failed to open breached password list: %v", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+				breached[strings.ToUpper(hash)] = struct{}{}
+			} else {
+				breached[passwordSHA1(line)] = struct{}{}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read breached password list: %v", err)
+		}
+		log.Printf("Loaded %d breached passwords", len(breached))
+	}
+
+	passwordConfig = cfg
+	breachedPasswords = breached
+
+	dummyPassword := make([]byte, 32)
+	if _, err := rand.Read(dummyPassword); err != nil {
+		return err
+	}
+	hash, err := HashPassword(hex.EncodeToString(dummyPassword))
+	if err != nil {
+		return err
+	}
+	dummyPasswordHash = hash
+	return nil
+}
+
+// ValidatePassword checks a new password against the password policy
+func ValidatePassword(password, username string) error {
+	if utf8.RuneCountInString(password) < passwordConfig.MinLength {
+		return fmt.Errorf("password must be at least %d characters", passwordConfig.MinLength)
+	}
+	if utf8.RuneCountInString(password) > passwordConfig.MaxLength {
+		return fmt.Errorf("password must be at most %d characters", passwordConfig.MaxLength)
+	}
+	// Characters outside ASCII take several bytes, a Thai character three
+	if passwordConfig.Algorithm == PasswordAlgorithmBcrypt && len(password) > bcryptMaxLength {
+		return fmt.Errorf("password must be at most %d bytes in UTF-8 with bcrypt hashing", bcryptMaxLength)
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return errors.New("password must not contain the username")
+	}
+	if _, found := breachedPasswords[passwordSHA1(password)]; found {
+		return errors.New("password must not be a known breached password")
+	}
+	return nil
+}
+
+// HashPassword hashes a password with the configured algorithm
+func HashPassword(password string) (string, error) {
+	if passwordConfig.Algorithm == PasswordAlgorithmArgon2id {
+		return hashArgon2id(password, passwordConfig)
+	}
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), passwordConfig.BcryptCost)
+	return string(bytes), err
+}
+
+// CheckPassword checks if a password matches its hash. Both argon2id and bcrypt hashes are understood.
+func CheckPassword(password, hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := parseArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		computed := argon2.IDKey([]byte(password), salt, params.Argon2Iterations, params.Argon2Memory, params.Argon2Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(computed, key) == 1
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return err == nil
+}
+
+// SimulatePasswordCheck takes as long as checking a password against a stored hash. It is used when
+// there is no hash to check, so a failed login does not tell unknown usernames apart by its timing.
+func SimulatePasswordCheck(password string) {
+	CheckPassword(password, dummyPasswordHash)
+}
+
+// PasswordNeedsRehash reports whether a hash was created with another algorithm or weaker parameters
+// than configured. Callers re-hash the password after it has been verified.
+func PasswordNeedsRehash(hash string) bool {
+	switch passwordConfig.Algorithm {
+	case PasswordAlgorithmArgon2id:
+		params, _, key, err := parseArgon2id(hash)
+		if err != nil {
+			return true
+		}
+		return params.Argon2Memory != passwordConfig.Argon2Memory ||
+			params.Argon2Iterations != passwordConfig.Argon2Iterations ||
+			params.Argon2Parallelism != passwordConfig.Argon2Parallelism ||
+			len(key) != argon2KeyLen
+	default:
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != passwordConfig.BcryptCost
+	}
+}
+
+// hashArgon2id encodes the hash in the PHC string format, e.g. "$argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>"
+func hashArgon2id(password string, cfg *PasswordConfig) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, cfg.Argon2Iterations, cfg.Argon2Memory, cfg.Argon2Parallelism, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		cfg.Argon2Memory, cfg.Argon2Iterations, cfg.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// parseArgon2id decodes a PHC formatted argon2id hash into its parameters, salt and key
+func parseArgon2id(hash string) (*PasswordConfig, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return nil, nil, nil, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, errors.New("unsupported argon2 version")
+	}
+
+	params := &PasswordConfig{Algorithm: PasswordAlgorithmArgon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Iterations, &params.Argon2Parallelism); err != nil {
+		return nil, nil, nil, errors.New("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, errors.New("invalid argon2id hash")
+	}
+	return params, salt, key, nil
+}
+
+func passwordSHA1(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func isSHA1Hex(value string) bool {
+	if len(value) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
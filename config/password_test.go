@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePasswordLength(t *testing.T) {
+	thai := strings.Repeat("รหัสผ่าน", 5) // 40 characters, 120 bytes
+
+	tests := []struct {
+		name      string
+		algorithm string
+		password  string
+		wantErr   string
+	}{
+		{name: "too short", algorithm: PasswordAlgorithmArgon2id, password: "ab1!xyz", wantErr: "password must be at least 8 characters"},
+		{name: "too many characters", algorithm: PasswordAlgorithmArgon2id, password: strings.Repeat("a", 129), wantErr: "password must be at most 128 characters"},
+		{name: "Thai password with argon2id", algorithm: PasswordAlgorithmArgon2id, password: thai},
+		{name: "Thai password over the bcrypt byte limit", algorithm: PasswordAlgorithmBcrypt, password: thai, wantErr: "password must be at most 72 bytes in UTF-8 with bcrypt hashing"},
+		{name: "Thai password within the bcrypt byte limit", algorithm: PasswordAlgorithmBcrypt, password: thai[:72]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := passwordConfig
+			t.Cleanup(func() { passwordConfig = previous })
+			passwordConfig = &PasswordConfig{Algorithm: tt.algorithm, MinLength: 8, MaxLength: 128}
+
+			err := ValidatePassword(tt.password, "somchai")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ValidatePassword() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("ValidatePassword() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// Registration modes
+const (
+	RegistrationDisabled          = "disabled"           // only admins create users
+	RegistrationOpen              = "open"               // new users can log in right away
+	RegistrationEmailVerification = "email_verification" // new users confirm their email address first
+	RegistrationAdminApproval     = "admin_approval"     // new users wait until an admin approves them
+)
+
+type RegistrationConfig struct {
+	Mode            string
+	DefaultRole     string        // role given to self-registered users
+	Organization    string        // slug of the organization self-registered users join
+	VerificationTTL time.Duration // lifetime of email verification links
+}
+
+func LoadRegistrationConfig() *RegistrationConfig {
+	mode := os.Getenv("REGISTRATION_MODE")
+	defaultRole := os.Getenv("REGISTRATION_DEFAULT_ROLE")
+	organization := os.Getenv("REGISTRATION_ORGANIZATION")
+
+	switch mode {
+	case RegistrationOpen, RegistrationEmailVerification, RegistrationAdminApproval, RegistrationDisabled:
+	case "":
+		mode = RegistrationDisabled
+	default:
+		log.Printf("Invalid value %q for REGISTRATION_MODE, registration is disabled", mode)
+		mode = RegistrationDisabled
+	}
+	if defaultRole == "" {
+		defaultRole = "user"
+	}
+	if organization == "" {
+		organization = "default"
+	}
+
+	return &RegistrationConfig{
+		Mode:            mode,
+		DefaultRole:     defaultRole,
+		Organization:    organization,
+		VerificationTTL: getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"go-gin-gorm-backend/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// SeedRolesAndPermissions creates the built-in permissions and roles.
+// The admin role is always granted every permission, including ones added later,
+// except organizations:manage which is reserved for the platform admin role.
+func SeedRolesAndPermissions(db *gorm.DB) {
+	permissions := []model.Permission{
+		{Name: model.PermissionTopicsRead, Description: "View topics and topic details"},
+		{Name: model.PermissionTopicsWrite, Description: "Create, update and delete topics and topic details"},
+		{Name: model.PermissionTopicsManage, Description: "Edit every topic and its details regardless of the owning team"},
+		{Name: model.PermissionUsersManage, Description: "Manage users and roles"},
+		{Name: model.PermissionAPIKeysManage, Description: "Manage API keys of all users"},
+		{Name: model.PermissionOrganizationsManage, Description: "Manage organizations and users of every organization"},
+	}
+	for _, p := range permissions {
+		var count int64
+		db.Model(&model.Permission{}).Where("name = ?", p.Name).Count(&count)
+		if count == 0 {
+			db.Create(&p)
+		}
+	}
+
+	roles := []struct {
+		role        model.Role
+		permissions []string
+	}{
+		{model.Role{Name: model.RoleAdmin, Description: "Full access"}, nil},
+		{model.Role{Name: model.RoleUser, Description: "Read and edit topics"}, []string{model.PermissionTopicsRead, model.PermissionTopicsWrite}},
+		{model.Role{Name: model.RoleViewer, Description: "Read-only access to topics"}, []string{model.PermissionTopicsRead}},
+		{model.Role{Name: model.RolePlatformAdmin, Description: "Manage all organizations"}, []string{model.PermissionOrganizationsManage}},
+	}
+	for _, r := range roles {
+		var role model.Role
+		if err := db.Where("name = ?", r.role.Name).First(&role).Error; err == nil {
+			if r.role.Name != model.RoleAdmin {
+				continue
+			}
+		} else {
+			role = r.role
+			if err := db.Create(&role).Error; err != nil {
+				log.Printf("Error creating role %s: %v", r.role.Name, err)
+				continue
+			}
+		}
+
+		var granted []model.Permission
+		if r.role.Name == model.RoleAdmin {
+			db.Where("name <> ?", model.PermissionOrganizationsManage).Find(&granted)
+		} else {
+			db.Where("name IN ?", r.permissions).Find(&granted)
+		}
+		if err := db.Model(&role).Omit("Permissions.*").Association("Permissions").Replace(granted); err != nil {
+			log.Printf("Error granting permissions to role %s: %v", r.role.Name, err)
+		}
+	}
+}
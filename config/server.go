@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+type ServerConfig struct {
+	// Reverse proxies (IP addresses or CIDR ranges) whose X-Forwarded-For header is trusted.
+	// Without any, the client IP is the address the request came from.
+	TrustedProxies []string
+}
+
+func LoadServerConfig() *ServerConfig {
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	return &ServerConfig{
+		TrustedProxies: trustedProxies,
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"time"
+)
+
+type TeamConfig struct {
+	CacheTTL time.Duration // how long the teams of a user are cached per instance, 0 disables the cache
+}
+
+func LoadTeamConfig() *TeamConfig {
+	return &TeamConfig{
+		CacheTTL: getEnvDuration("TEAM_CACHE_TTL", 30*time.Second),
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// Token revocation stores
+const (
+	TokenRevocationStoreDatabase = "database" // shared by all instances
+	TokenRevocationStoreMemory   = "memory"   // single-instance setups only
+)
+
+type TokenRevocationConfig struct {
+	Store    string
+	CacheTTL time.Duration // how long per-user revocations of the database store are cached, 0 disables the cache
+}
+
+func LoadTokenRevocationConfig() *TokenRevocationConfig {
+	store := os.Getenv("TOKEN_REVOCATION_STORE")
+	if store != TokenRevocationStoreMemory {
+		store = TokenRevocationStoreDatabase
+	}
+
+	return &TokenRevocationConfig{
+		Store:    store,
+		CacheTTL: getEnvDuration("TOKEN_REVOCATION_CACHE_TTL", 30*time.Second),
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type TwoFactorConfig struct {
+	Issuer       string        // shown as the account issuer in authenticator apps
+	ChallengeTTL time.Duration // lifetime of the challenge token between password and code
+}
+
+func LoadTwoFactorConfig() *TwoFactorConfig {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "Go Gin GORM Backend"
+	}
+
+	return &TwoFactorConfig{
+		Issuer:       issuer,
+		ChallengeTTL: getEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+	}
+}
@@ -25,13 +25,22 @@ func SeedAdminUser(db *gorm.DB) {
 		return
 	}
 
+	// The first admin also manages organizations
+	var adminRoles []model.Role
+	if err := db.Where("name IN ?", []string{model.RoleAdmin, model.RolePlatformAdmin}).Find(&adminRoles).Error; err != nil || len(adminRoles) == 0 {
+		log.Printf("Could not find admin role: %v", err)
+		return
+	}
+
 	adminUser := &model.User{
-		Username: "admin",
-		Email:    "admin@example.com",
-		Password: hashedPassword,
-		FullName: "System Administrator",
-		Role:     "admin",
-		IsActive: true,
+		Username:           "admin",
+		Email:              "admin@example.com",
+		Password:           hashedPassword,
+		FullName:           "System Administrator",
+		Roles:              adminRoles,
+		TenantID:           defaultOrganizationID(db),
+		IsActive:           true,
+		MustChangePassword: true,
 	}
 
 	if err := userRepo.CreateUser(adminUser); err != nil {
@@ -42,5 +51,5 @@ func SeedAdminUser(db *gorm.DB) {
 	log.Println("Admin user created successfully")
 	log.Println("Username: admin")
 	log.Println("Password: admin123")
-	log.Println("Please change the password after first login!")
+	log.Println("The password must be changed via POST /auth/password after first login!")
 }
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// canManageAllAPIKeys reports whether the caller may manage the API keys of other users
+func canManageAllAPIKeys(c *gin.Context) bool {
+	return slices.Contains(c.GetStringSlice("permissions"), model.PermissionAPIKeysManage)
+}
+
+// GetAllAPIKeys godoc
+// @Summary Get API keys
+// @Description List the API keys of the current user, or of all users with the api_keys:manage permission
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.APIKeyResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /api-keys [get]
+func (h *APIKeyHandler) GetAllAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request.Context(), c.GetUint("user_id"), canManageAllAPIKeys(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key for the current user, or for another user (such as a service account) with the api_keys:manage permission. Scopes must be permissions the caller holds, and keys for other users must be scoped. Keys cannot be created with an API key. The key is only returned once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key body model.CreateAPIKeyRequest true "API key request object"
+// @Success 201 {object} model.CreateAPIKeyResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var keyRequest model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&keyRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), c.GetUint("user_id"), c.GetStringSlice("permissions"), canManageAllAPIKeys(c), &keyRequest)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Tags api-keys
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID format"})
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), uint(id), c.GetUint("user_id"), canManageAllAPIKeys(c)); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"go-gin-gorm-backend/config"
 	"go-gin-gorm-backend/model"
 	"go-gin-gorm-backend/service"
 
@@ -10,11 +11,17 @@ import (
 )
 
 type AuthHandler struct {
-	userService *service.UserService
+	userService          *service.UserService
+	tokenService         *service.TokenService
+	passwordResetService *service.PasswordResetService
 }
 
-func NewAuthHandler(userService *service.UserService) *AuthHandler {
-	return &AuthHandler{userService: userService}
+func NewAuthHandler(userService *service.UserService, tokenService *service.TokenService, passwordResetService *service.PasswordResetService) *AuthHandler {
+	return &AuthHandler{
+		userService:          userService,
+		tokenService:         tokenService,
+		passwordResetService: passwordResetService,
+	}
 }
 
 // Login godoc
@@ -27,6 +34,7 @@ func NewAuthHandler(userService *service.UserService) *AuthHandler {
 // @Success 200 {object} model.LoginResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req model.LoginRequest
@@ -37,7 +45,43 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.userService.LoginUser(&req)
+	response, err := h.userService.LoginUser(&req, clientInfo(c))
+	if err != nil {
+		status := http.StatusUnauthorized
+		switch err.Error() {
+		case "account temporarily locked", "too many failed login attempts":
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, model.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can only be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} model.LoginResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := h.tokenService.RefreshTokens(req.RefreshToken, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
 			Error: err.Error(),
@@ -47,3 +91,137 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// Logout godoc
+// @Summary Logout user
+// @Description Revoke the current access token and its refresh tokens
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: "Authentication required",
+		})
+		return
+	}
+
+	if err := h.tokenService.Logout(claims.(*model.Claims), clientInfo(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the password of the authenticated user. All other sessions are revoked and new tokens are returned.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} model.LoginResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /auth/password [post]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := h.userService.ChangePassword(c.GetUint("user_id"), &req, clientInfo(c))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Mail a one-time password reset link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} model.MessageResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.passwordResetService.RequestPasswordReset(&req); err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.MessageResponse{
+		Message: "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using a one-time reset token. All sessions of the user are revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(&req, clientInfo(c)); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.MessageResponse{
+		Message: "Password has been reset",
+	})
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys that verify access tokens, identified by the kid token header
+// @Tags auth
+// @Produce json
+// @Success 200 {object} model.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, config.PublicJWKS())
+}
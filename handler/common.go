@@ -2,22 +2,57 @@ package handler
 
 import (
 	"net/http"
+	"strings"
+
+	"go-gin-gorm-backend/model"
 
 	"github.com/gin-gonic/gin"
 )
 
+// clientInfo describes the client of the current request for session tracking
+func clientInfo(c *gin.Context) model.ClientInfo {
+	return model.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
 // handleErrorResponse is a helper function to handle error responses consistently
 func handleErrorResponse(c *gin.Context, err error) {
 	switch err.Error() {
-	case "topic not found", "topic detail not found":
+	case "topic not found", "topic detail not found", "user not found", "role not found", "permission not found",
+		"api key not found", "session not found", "organization not found",
+		"oauth client not found", "impersonation not found",
+		"invitation not found", "team not found", "topic acl entry not found":
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-	case "topic name already exists", "topic detail name already exists":
+	case "topic name already exists", "topic name is not available",
+		"topic detail name already exists", "topic detail name is not available",
+		"username already exists", "email already exists", "role name already exists",
+		"organization name already exists", "organization slug already exists", "invalid organization slug",
+		"team name already exists":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case "cannot deactivate your own account", "cannot delete your own account",
+		"cannot delete the admin role", "cannot change the permissions of the admin role",
+		"current password is incorrect", "new password must be different from the current password",
+		"invalid or expired reset token", "password is required", "expiry must be in the future",
+		"two-factor authentication is already enabled", "two-factor authentication is not enabled",
+		"two-factor enrollment has not been started", "two-factor authentication is required for your role",
+		"invalid two-factor code", "invalid or expired verification token", "user is not awaiting approval",
+		"cannot erase your own account here", "cannot impersonate yourself", "cannot impersonate an inactive user",
+		"impersonation has already ended", "email has already been invited", "invitation is no longer open",
+		"invalid or expired invitation", "scopes are required for keys of other users", "cannot remove your own admin access to the topic":
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case "registration is disabled":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "insufficient permissions":
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
 	case "order number already exists":
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Order number already exists"})
 	case "invalid topic ID format":
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Topic ID format"})
 	default:
+		// Password policy violations name the configured limits, so they cannot be matched exactly
+		if strings.HasPrefix(err.Error(), "password must ") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
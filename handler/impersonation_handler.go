@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+}
+
+func NewImpersonationHandler(impersonationService *service.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{impersonationService: impersonationService}
+}
+
+// ImpersonateUser godoc
+// @Summary Impersonate a user
+// @Description Issue a short-lived token that acts as the user on behalf of the admin (admin only). The token carries an act claim naming the admin, changes made with it record both users, and it has no refresh token. Changing credentials and starting further impersonations are not allowed with it. Refused with an API key.
+// @Tags impersonation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body model.StartImpersonationRequest true "Reason for the impersonation"
+// @Success 201 {object} model.ImpersonationResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /users/{id}/impersonate [post]
+func (h *ImpersonationHandler) ImpersonateUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.impersonationService.StartImpersonation(c.Request.Context(), c.GetUint("user_id"), id, &req, canManageOrganizations(c))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetAllImpersonations godoc
+// @Summary Get impersonations
+// @Description List the impersonations of the caller's organization, newest first (admin only)
+// @Tags impersonation
+// @Produce json
+// @Security BearerAuth
+// @Param active query bool false "Only list impersonations that have not ended or expired"
+// @Success 200 {array} model.Impersonation
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /impersonations [get]
+func (h *ImpersonationHandler) GetAllImpersonations(c *gin.Context) {
+	activeOnly, _ := strconv.ParseBool(c.Query("active"))
+
+	impersonations, err := h.impersonationService.ListImpersonations(c.Request.Context(), activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, impersonations)
+}
+
+// EndImpersonation godoc
+// @Summary End an impersonation
+// @Description End an impersonation and revoke its token (admin only)
+// @Tags impersonation
+// @Security BearerAuth
+// @Param id path string true "Impersonation ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /impersonations/{id} [delete]
+func (h *ImpersonationHandler) EndImpersonation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid impersonation ID format"})
+		return
+	}
+
+	if err := h.impersonationService.EndImpersonation(c.Request.Context(), uint(id)); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	invitationService *service.InvitationService
+}
+
+func NewInvitationHandler(invitationService *service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService}
+}
+
+// parseInvitationID reads the invitation ID path parameter and writes a 400 response when it is invalid
+func parseInvitationID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetAllInvitations godoc
+// @Summary Get invitations
+// @Description List the invitations of the caller's organization, newest first (admin only)
+// @Tags invitations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.InvitationResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /invitations [get]
+func (h *InvitationHandler) GetAllInvitations(c *gin.Context) {
+	invitations, err := h.invitationService.ListInvitations(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// CreateInvitation godoc
+// @Summary Invite a user
+// @Description Invite someone by email with a pre-assigned role (admin only). They get a one-time link to choose a username and password. Other organizations than the caller's need the organizations:manage permission. Refused with an API key.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invitation body model.CreateInvitationRequest true "Invitation request object"
+// @Success 201 {object} model.InvitationResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req model.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(c.Request.Context(), &req, canManageOrganizations(c))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ResendInvitation godoc
+// @Summary Resend an invitation
+// @Description Mail a new link for an invitation that has not been accepted or revoked and restart its expiry. The previous link stops working (admin only). Refused with an API key.
+// @Tags invitations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} model.InvitationResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /invitations/{id}/resend [post]
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	id, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.invitationService.ResendInvitation(c.Request.Context(), id)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invitation)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Withdraw an invitation that has not been accepted yet so its link stops working (admin only)
+// @Tags invitations
+// @Security BearerAuth
+// @Param id path string true "Invitation ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /invitations/{id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	id, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.invitationService.RevokeInvitation(c.Request.Context(), id); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Create an account with the token from an invitation mail. The account gets the invited role and organization and can log in right away.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body model.AcceptInvitationRequest true "Invitation token, username and password"
+// @Success 201 {object} model.UserResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 500 {object} model.InternalServerError
+// @Router /auth/invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req model.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.invitationService.AcceptInvitation(&req)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
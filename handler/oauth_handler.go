@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthHandler struct {
+	oauthService *service.OAuthService
+}
+
+func NewOAuthHandler(oauthService *service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// Token godoc
+// @Summary Get an access token
+// @Description OAuth2 token endpoint. Supports the client_credentials grant; client credentials are sent as form fields or with HTTP Basic authentication. The token carries the granted scopes as permissions.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Grant type" Enums(client_credentials)
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Param scope formData string false "Space separated scopes, defaults to all scopes of the client"
+// @Success 200 {object} model.OAuthTokenResponse
+// @Failure 400 {object} model.OAuthErrorResponse
+// @Failure 401 {object} model.OAuthErrorResponse
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	// Token responses must not be cached (RFC 6749 section 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var tokenRequest model.OAuthTokenRequest
+	if err := c.ShouldBind(&tokenRequest); err != nil {
+		c.JSON(http.StatusBadRequest, model.OAuthErrorResponse{Error: service.OAuthErrorInvalidRequest, ErrorDescription: err.Error()})
+		return
+	}
+
+	// Basic credentials are form-encoded before they are joined (RFC 6749 section 2.3.1)
+	clientID, clientSecret, basicAuth := c.Request.BasicAuth()
+	if basicAuth {
+		var err error
+		if tokenRequest.ClientID, err = url.QueryUnescape(clientID); err == nil {
+			tokenRequest.ClientSecret, err = url.QueryUnescape(clientSecret)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.OAuthErrorResponse{Error: service.OAuthErrorInvalidRequest, ErrorDescription: "malformed client credentials"})
+			return
+		}
+	}
+
+	response, err := h.oauthService.IssueToken(&tokenRequest)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if !errors.As(err, &oauthErr) {
+			c.JSON(http.StatusInternalServerError, model.OAuthErrorResponse{Error: "server_error"})
+			return
+		}
+
+		status := http.StatusBadRequest
+		if oauthErr.Code == service.OAuthErrorInvalidClient {
+			status = http.StatusUnauthorized
+			if basicAuth {
+				c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			}
+		}
+		c.JSON(status, model.OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAllClients godoc
+// @Summary Get OAuth clients
+// @Description List the OAuth clients of the caller's organization (admin only)
+// @Tags oauth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.OAuthClientResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /oauth/clients [get]
+func (h *OAuthHandler) GetAllClients(c *gin.Context) {
+	clients, err := h.oauthService.ListClients(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, clients)
+}
+
+// CreateClient godoc
+// @Summary Register an OAuth client
+// @Description Register a client for the client_credentials grant in the caller's organization (admin only). Scopes are permission names. The secret is only returned once. Refused with an API key.
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client body model.CreateOAuthClientRequest true "OAuth client request object"
+// @Success 201 {object} model.CreateOAuthClientResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /oauth/clients [post]
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	var clientRequest model.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&clientRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, err := h.oauthService.CreateClient(c.Request.Context(), &clientRequest, canManageOrganizations(c))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, client)
+}
+
+// RevokeClient godoc
+// @Summary Revoke an OAuth client
+// @Description Revoke a client and every access token issued to it (admin only)
+// @Tags oauth
+// @Security BearerAuth
+// @Param id path string true "OAuth client ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /oauth/clients/{id} [delete]
+func (h *OAuthHandler) RevokeClient(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OAuth client ID format"})
+		return
+	}
+
+	if err := h.oauthService.RevokeClient(c.Request.Context(), uint(id)); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
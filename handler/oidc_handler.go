@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/auth/oidc"
+)
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+	secure      bool // send the state cookie over HTTPS only
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService, redirectURL string) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService, secure: strings.HasPrefix(redirectURL, "https://")}
+}
+
+// Login godoc
+// @Summary Start single sign-on
+// @Description Redirect the browser to the OpenID Connect provider to log in
+// @Tags auth
+// @Success 302 "Redirect to the identity provider"
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 502 {object} model.ErrorResponse
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	if !h.oidcService.Enabled() {
+		c.JSON(http.StatusNotFound, model.ErrorResponse{Error: "single sign-on is not configured"})
+		return
+	}
+
+	authURL, state, err := h.oidcService.StartLogin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusBadGateway, model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, 600, oidcStateCookiePath, "", h.secure, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary Complete single sign-on
+// @Description Exchange the authorization code returned by the OpenID Connect provider for our JWT tokens. Unknown users are provisioned automatically when enabled. Users with 2FA enabled get a challenge token to complete at /auth/2fa/verify instead.
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} model.LoginResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if !h.oidcService.Enabled() {
+		c.JSON(http.StatusNotFound, model.ErrorResponse{Error: "single sign-on is not configured"})
+		return
+	}
+
+	if errorCode := c.Query("error"); errorCode != "" {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Error: "identity provider returned " + errorCode})
+		return
+	}
+
+	browserState, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcStateCookiePath, "", h.secure, true)
+
+	response, err := h.oidcService.CompleteLogin(c.Request.Context(), c.Query("state"), browserState, c.Query("code"), clientInfo(c))
+	if err != nil {
+		status := http.StatusUnauthorized
+		switch err.Error() {
+		case "invalid or expired login state":
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, model.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService}
+}
+
+// parseOrganizationID reads the organization ID path parameter and writes a 400 response when it is invalid
+func parseOrganizationID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetAllOrganizations godoc
+// @Summary Get all organizations
+// @Description Requires the organizations:manage permission
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Organization
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /organizations [get]
+func (h *OrganizationHandler) GetAllOrganizations(c *gin.Context) {
+	organizations, err := h.organizationService.ListOrganizations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, organizations)
+}
+
+// GetOrganizationByID godoc
+// @Summary Get an organization by ID
+// @Description Requires the organizations:manage permission
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Success 200 {object} model.Organization
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Router /organizations/{id} [get]
+func (h *OrganizationHandler) GetOrganizationByID(c *gin.Context) {
+	id, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	organization, err := h.organizationService.GetOrganization(id)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, organization)
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization
+// @Description Create a new tenant. Add users to it with tenant_id on POST /users. Requires the organizations:manage permission.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param organization body model.CreateOrganizationRequest true "Organization request object"
+// @Success 201 {object} model.Organization
+// @Failure 400 {object} model.BadRequestError
+// @Failure 500 {object} model.InternalServerError
+// @Router /organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var organizationRequest model.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&organizationRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.organizationService.CreateOrganization(&organizationRequest)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, organization)
+}
+
+// UpdateOrganization godoc
+// @Summary Update an organization
+// @Description Requires the organizations:manage permission
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Param organization body model.UpdateOrganizationRequest true "Updated organization object"
+// @Success 200 {object} model.Organization
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /organizations/{id} [put]
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	id, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var organizationRequest model.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&organizationRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.organizationService.UpdateOrganization(id, &organizationRequest)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
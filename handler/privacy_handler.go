@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyHandler struct {
+	privacyService *service.PrivacyService
+}
+
+func NewPrivacyHandler(privacyService *service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{privacyService: privacyService}
+}
+
+// ExportMyData godoc
+// @Summary Export my personal data
+// @Description Download a zip archive with the account, the topics and details authored, the sessions, login history, SSO identities, API keys and impersonations of the authenticated user
+// @Tags auth
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file "Zip archive of JSON files"
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /auth/me/export [get]
+func (h *PrivacyHandler) ExportMyData(c *gin.Context) {
+	h.export(c, c.GetUint("user_id"))
+}
+
+// EraseMe godoc
+// @Summary Erase my account
+// @Description Anonymize the account of the authenticated user and sign out everywhere. Topics and details stay and are credited to the anonymized account.
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param request body model.EraseAccountRequest true "Password confirmation"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /auth/me/erase [post]
+func (h *PrivacyHandler) EraseMe(c *gin.Context) {
+	var req model.EraseAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.privacyService.EraseOwnAccount(c.GetUint("user_id"), &req); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ExportUserData godoc
+// @Summary Export the personal data of a user
+// @Description Download a zip archive with the personal data of a user, for data subject access requests (admin only)
+// @Tags users
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {file} file "Zip archive of JSON files"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /users/{id}/export [get]
+func (h *PrivacyHandler) ExportUserData(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	h.export(c, id)
+}
+
+// EraseUser godoc
+// @Summary Erase a user
+// @Description Anonymize a user for a data subject erasure request and sign them out everywhere (admin only). Topics and details stay and are credited to the anonymized account.
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /users/{id}/erase [post]
+func (h *PrivacyHandler) EraseUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.privacyService.EraseUser(id, c.GetUint("user_id")); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *PrivacyHandler) export(c *gin.Context, userID uint) {
+	archive, filename, err := h.privacyService.ExportPersonalData(userID)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
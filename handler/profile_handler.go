@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileHandler struct {
+	profileService *service.ProfileService
+}
+
+func NewProfileHandler(profileService *service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{profileService: profileService}
+}
+
+// GetMe godoc
+// @Summary Get the current user
+// @Description Return the authenticated user as currently stored, rather than as captured in the token
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.UserResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Router /auth/me [get]
+func (h *ProfileHandler) GetMe(c *gin.Context) {
+	user, err := h.profileService.GetProfile(c.GetUint("user_id"))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateMe godoc
+// @Summary Update the current user
+// @Description Update the full name, email address and preferences of the authenticated user. A new email address is returned as pending_email and replaces the current one after it has been confirmed through the mailed link (POST /auth/verify-email). Refused with an impersonation token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.UpdateProfileRequest true "Profile fields to change"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.ErrorResponse
+// @Router /auth/me [patch]
+func (h *ProfileHandler) UpdateMe(c *gin.Context) {
+	var req model.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := h.profileService.UpdateProfile(c.GetUint("user_id"), &req)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
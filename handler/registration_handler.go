@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RegistrationHandler struct {
+	registrationService *service.RegistrationService
+}
+
+func NewRegistrationHandler(registrationService *service.RegistrationService) *RegistrationHandler {
+	return &RegistrationHandler{registrationService: registrationService}
+}
+
+// Register godoc
+// @Summary Register an account
+// @Description Create an account. Depending on the deployment the account is usable right away, after verifying the email address, or after an admin approved it.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RegisterRequest true "Account details"
+// @Success 201 {object} model.RegisterResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /auth/register [post]
+func (h *RegistrationHandler) Register(c *gin.Context) {
+	var req model.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := h.registrationService.Register(&req)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Confirm an email address with the one-time token from the verification mail
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *RegistrationHandler) VerifyEmail(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := h.registrationService.VerifyEmail(&req)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification mail
+// @Description Mail a new verification link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.ResendVerificationRequest true "Account email"
+// @Success 202 {object} model.MessageResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /auth/verify-email/resend [post]
+func (h *RegistrationHandler) ResendVerification(c *gin.Context) {
+	var req model.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.registrationService.ResendVerification(&req); err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.MessageResponse{
+		Message: "If the email is awaiting verification, a new link has been sent",
+	})
+}
+
+// ApproveUser godoc
+// @Summary Approve a registration
+// @Description Activate a self-registered user waiting for approval (admin only)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /users/{id}/approve [post]
+func (h *RegistrationHandler) ApproveUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.registrationService.ApproveUser(id)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
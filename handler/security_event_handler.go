@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SecurityEventHandler struct {
+	securityEventService *service.SecurityEventService
+}
+
+func NewSecurityEventHandler(securityEventService *service.SecurityEventService) *SecurityEventHandler {
+	return &SecurityEventHandler{securityEventService: securityEventService}
+}
+
+// GetSecurityEvents godoc
+// @Summary Get security events
+// @Description List login attempts, token refreshes, logouts, password and role changes of the caller's organization, newest first (admin only). Failed logins of unknown usernames belong to no organization and are only listed for callers with organizations:manage, who see the events of every organization.
+// @Tags security-events
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Event type" Enums(login, login_2fa, login_oidc, token_refresh, logout, password_change, password_reset, role_change)
+// @Param user_id query int false "User ID"
+// @Param username query string false "Username, including attempted names that do not exist"
+// @Param ip_address query string false "Client IP address"
+// @Param success query bool false "Only successful or only failed events"
+// @Param since query string false "Earliest time (RFC 3339)"
+// @Param until query string false "Latest time (RFC 3339)"
+// @Param limit query int false "Maximum number of events (1-1000, default 100)"
+// @Param offset query int false "Number of events to skip"
+// @Success 200 {array} model.SecurityEvent
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /security-events [get]
+func (h *SecurityEventHandler) GetSecurityEvents(c *gin.Context) {
+	var query model.SecurityEventQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	events, err := h.securityEventService.ListEvents(c.Request.Context(), &query, canManageOrganizations(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
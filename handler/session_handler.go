@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionService *service.SessionService
+}
+
+func NewSessionHandler(sessionService *service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// currentSessionID returns the session the request was authenticated with, if any
+func currentSessionID(c *gin.Context) string {
+	if claims, ok := c.Get("claims"); ok {
+		return claims.(*model.Claims).SessionID
+	}
+	return ""
+}
+
+// parseSessionID reads a session ID path parameter and writes a 400 response when it is malformed
+func parseSessionID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetMySessions godoc
+// @Summary Get my sessions
+// @Description List the devices the current user is signed in on. The session of the request is marked as current.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.SessionResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /auth/sessions [get]
+func (h *SessionHandler) GetMySessions(c *gin.Context) {
+	sessions, err := h.sessionService.ListSessions(c.GetUint("user_id"), currentSessionID(c))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeMySession godoc
+// @Summary Sign out a device
+// @Description Revoke one of the current user's sessions. Its refresh token and access tokens stop working immediately.
+// @Tags auth
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /auth/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	id, ok := parseSessionID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.GetUint("user_id"), id); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetUserSessions godoc
+// @Summary Get sessions of a user
+// @Description List the devices a user is signed in on (admin only)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {array} model.SessionResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /users/{id}/sessions [get]
+func (h *SessionHandler) GetUserSessions(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	currentID := ""
+	if id == c.GetUint("user_id") {
+		currentID = currentSessionID(c)
+	}
+
+	sessions, err := h.sessionService.ListSessions(id, currentID)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeUserSession godoc
+// @Summary Revoke a session of a user
+// @Description Sign a user out of a single device (admin only)
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param sessionId path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /users/{id}/sessions/{sessionId} [delete]
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseSessionID(c, "sessionId")
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(userID, id); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeamHandler struct {
+	teamService *service.TeamService
+}
+
+func NewTeamHandler(teamService *service.TeamService) *TeamHandler {
+	return &TeamHandler{teamService: teamService}
+}
+
+// parseTeamID reads the team ID path parameter and writes a 400 response when it is invalid
+func parseTeamID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseTeamMember reads the team ID and user ID path parameters and writes a 400 response when one is invalid
+func parseTeamMember(c *gin.Context) (uint, uint, bool) {
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return 0, 0, false
+	}
+	return teamID, uint(userID), true
+}
+
+// GetAllTeams godoc
+// @Summary Get all teams
+// @Description List the teams of the caller's organization with their permissions and members (admin only)
+// @Tags teams
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.TeamResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.InternalServerError
+// @Router /teams [get]
+func (h *TeamHandler) GetAllTeams(c *gin.Context) {
+	teams, err := h.teamService.ListTeams(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
+
+// GetTeamByID godoc
+// @Summary Get a team by ID
+// @Tags teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Success 200 {object} model.TeamResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Router /teams/{id} [get]
+func (h *TeamHandler) GetTeamByID(c *gin.Context) {
+	id, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	team, err := h.teamService.GetTeam(c.Request.Context(), id)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+// CreateTeam godoc
+// @Summary Create a team
+// @Description Create a team in the caller's organization. Members get the permissions of the team on top of those of their roles (admin only).
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param team body model.CreateTeamRequest true "Team request object"
+// @Success 201 {object} model.TeamResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /teams [post]
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	var teamRequest model.CreateTeamRequest
+	if err := c.ShouldBindJSON(&teamRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.teamService.CreateTeam(c.Request.Context(), &teamRequest, canManageOrganizations(c))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+// UpdateTeam godoc
+// @Summary Update a team
+// @Description Update the name, description or permissions of a team. Members get changed permissions with their next request (admin only).
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param team body model.UpdateTeamRequest true "Updated team object"
+// @Success 200 {object} model.TeamResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /teams/{id} [put]
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	id, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	var teamRequest model.UpdateTeamRequest
+	if err := c.ShouldBindJSON(&teamRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.teamService.UpdateTeam(c.Request.Context(), id, &teamRequest, canManageOrganizations(c))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// DeleteTeam godoc
+// @Summary Delete a team
+// @Description Delete a team and its memberships. Topics it owned no longer have an owning team (admin only).
+// @Tags teams
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /teams/{id} [delete]
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	id, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.teamService.DeleteTeam(c.Request.Context(), id, canManageOrganizations(c)); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AddTeamMember godoc
+// @Summary Add a team member
+// @Description Add a user of the caller's organization to a team (admin only)
+// @Tags teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} model.TeamResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /teams/{id}/members/{userId} [put]
+func (h *TeamHandler) AddTeamMember(c *gin.Context) {
+	teamID, userID, ok := parseTeamMember(c)
+	if !ok {
+		return
+	}
+
+	team, err := h.teamService.AddMember(c.Request.Context(), teamID, userID, canManageOrganizations(c))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+// RemoveTeamMember godoc
+// @Summary Remove a team member
+// @Description Remove a user from a team (admin only)
+// @Tags teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} model.TeamResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /teams/{id}/members/{userId} [delete]
+func (h *TeamHandler) RemoveTeamMember(c *gin.Context) {
+	teamID, userID, ok := parseTeamMember(c)
+	if !ok {
+		return
+	}
+
+	team, err := h.teamService.RemoveMember(c.Request.Context(), teamID, userID)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
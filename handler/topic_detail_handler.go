@@ -22,10 +22,13 @@ func NewTopicDetailHandler(service service.TopicDetailService) *TopicDetailHandl
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Topic ID"
 // @Param detail body model.CreateTopicDetailRequest true "Topic Detail object"
 // @Success 201 {object} model.TopicDetail
 // @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
 // @Failure 500 {object} model.InternalServerError
 // @Router /topics/{id}/details [post]
 func (h *TopicDetailHandler) CreateTopicDetail(c *gin.Context) {
@@ -41,7 +44,7 @@ func (h *TopicDetailHandler) CreateTopicDetail(c *gin.Context) {
 		return
 	}
 
-	detail, err := h.Service.CreateTopicDetailWithValidation(topicID, &detailRequest)
+	detail, err := h.Service.CreateTopicDetailWithValidation(c.Request.Context(), topicID, &detailRequest)
 	if err != nil {
 		handleErrorResponse(c, err)
 		return
@@ -55,9 +58,11 @@ func (h *TopicDetailHandler) CreateTopicDetail(c *gin.Context) {
 // @Tags topic-details
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Topic ID"
 // @Success 200 {array} model.TopicDetail
 // @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
 // @Failure 500 {object} model.InternalServerError
 // @Router /topics/{id}/details [get]
 func (h *TopicDetailHandler) GetAllDetailsByTopicID(c *gin.Context) {
@@ -67,9 +72,9 @@ func (h *TopicDetailHandler) GetAllDetailsByTopicID(c *gin.Context) {
 		return
 	}
 
-	details, err := h.Service.GetAllDetailsByTopicID(topicID)
+	details, err := h.Service.GetAllDetailsByTopicID(c.Request.Context(), topicID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleErrorResponse(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, details)
@@ -80,6 +85,7 @@ func (h *TopicDetailHandler) GetAllDetailsByTopicID(c *gin.Context) {
 // @Tags topic-details
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Topic Detail ID"
 // @Success 200 {object} model.TopicDetail
 // @Failure 400 {object} model.BadRequestError
@@ -92,7 +98,7 @@ func (h *TopicDetailHandler) GetDetailByID(c *gin.Context) {
 		return
 	}
 
-	detail, err := h.Service.GetDetailByID(id)
+	detail, err := h.Service.GetDetailByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -106,10 +112,12 @@ func (h *TopicDetailHandler) GetDetailByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Topic Detail ID"
 // @Param detail body model.UpdateTopicDetailRequest true "Updated Topic Detail object"
 // @Success 200 {object} model.TopicDetail
 // @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 404 {object} model.NotFoundError
 // @Failure 500 {object} model.InternalServerError
 // @Router /details/{id} [put]
@@ -126,7 +134,7 @@ func (h *TopicDetailHandler) UpdateTopicDetail(c *gin.Context) {
 		return
 	}
 
-	detail, err := h.Service.UpdateTopicDetailWithValidation(id, &detailRequest)
+	detail, err := h.Service.UpdateTopicDetailWithValidation(c.Request.Context(), id, &detailRequest)
 	if err != nil {
 		handleErrorResponse(c, err)
 		return
@@ -139,9 +147,12 @@ func (h *TopicDetailHandler) UpdateTopicDetail(c *gin.Context) {
 // @Summary Delete a topic detail
 // @Tags topic-details
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Topic Detail ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
 // @Failure 500 {object} model.InternalServerError
 // @Router /details/{id} [delete]
 func (h *TopicDetailHandler) DeleteTopicDetail(c *gin.Context) {
@@ -151,8 +162,8 @@ func (h *TopicDetailHandler) DeleteTopicDetail(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.DeleteTopicDetail(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Service.DeleteTopicDetail(c.Request.Context(), id); err != nil {
+		handleErrorResponse(c, err)
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
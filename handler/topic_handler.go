@@ -4,6 +4,7 @@ import (
 	"go-gin-gorm-backend/model"
 	"go-gin-gorm-backend/service"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,9 +23,12 @@ func NewTopicHandler(service service.TopicService) *TopicHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param topic body model.CreateTopicRequest true "Topic request object"
 // @Success 201 {object} model.Topic
 // @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
 // @Failure 500 {object} model.InternalServerError
 // @Router /topics [post]
 func (h *TopicHandler) CreateTopic(c *gin.Context) {
@@ -34,7 +38,7 @@ func (h *TopicHandler) CreateTopic(c *gin.Context) {
 		return
 	}
 
-	topic, err := h.Service.CreateTopicWithValidation(&topicRequest)
+	topic, err := h.Service.CreateTopicWithValidation(c.Request.Context(), &topicRequest)
 	if err != nil {
 		handleErrorResponse(c, err)
 		return
@@ -48,11 +52,12 @@ func (h *TopicHandler) CreateTopic(c *gin.Context) {
 // @Tags topics
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Success 200 {array} model.Topic
 // @Failure 500 {object} model.InternalServerError
 // @Router /topics [get]
 func (h *TopicHandler) GetAllTopics(c *gin.Context) {
-	topics, err := h.Service.GetAllTopics()
+	topics, err := h.Service.GetAllTopics(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -65,6 +70,7 @@ func (h *TopicHandler) GetAllTopics(c *gin.Context) {
 // @Tags topics
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Topic ID"
 // @Success 200 {object} model.Topic
 // @Failure 400 {object} model.BadRequestError
@@ -77,7 +83,7 @@ func (h *TopicHandler) GetTopicByID(c *gin.Context) {
 		return
 	}
 
-	topic, err := h.Service.GetTopicByID(id)
+	topic, err := h.Service.GetTopicByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -91,10 +97,12 @@ func (h *TopicHandler) GetTopicByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Topic ID"
 // @Param topic body model.UpdateTopicRequest true "Updated Topic request object"
 // @Success 200 {object} model.Topic
 // @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 404 {object} model.NotFoundError
 // @Failure 500 {object} model.InternalServerError
 // @Router /topics/{id} [put]
@@ -111,7 +119,7 @@ func (h *TopicHandler) UpdateTopic(c *gin.Context) {
 		return
 	}
 
-	topic, err := h.Service.UpdateTopicWithValidation(id, &topicRequest)
+	topic, err := h.Service.UpdateTopicWithValidation(c.Request.Context(), id, &topicRequest)
 	if err != nil {
 		handleErrorResponse(c, err)
 		return
@@ -124,9 +132,12 @@ func (h *TopicHandler) UpdateTopic(c *gin.Context) {
 // @Summary Delete a topic
 // @Tags topics
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Topic ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
 // @Failure 500 {object} model.InternalServerError
 // @Router /topics/{id} [delete]
 func (h *TopicHandler) DeleteTopic(c *gin.Context) {
@@ -136,8 +147,107 @@ func (h *TopicHandler) DeleteTopic(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.DeleteTopic(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Service.DeleteTopic(c.Request.Context(), id); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetTopicACL godoc
+// @Summary Get the access control list of a topic
+// @Description List the users and teams granted access to a topic. A topic without entries is open to everyone of the organization. Requires admin access to the topic.
+// @Tags topics
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Topic ID"
+// @Success 200 {array} model.TopicACLEntry
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /topics/{id}/acl [get]
+func (h *TopicHandler) GetTopicACL(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID is required"})
+		return
+	}
+
+	entries, err := h.Service.GetTopicACL(c.Request.Context(), id)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// SetTopicACLEntry godoc
+// @Summary Grant access to a topic
+// @Description Grant a user or a team read, write or admin access to a topic, replacing the access they had. Once a topic has entries, only the users and teams listed, members of the owning team and users holding topics:manage can access it. Requires admin access to the topic.
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Topic ID"
+// @Param entry body model.SetTopicACLEntryRequest true "User or team and access level"
+// @Success 200 {array} model.TopicACLEntry
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /topics/{id}/acl [put]
+func (h *TopicHandler) SetTopicACLEntry(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID is required"})
+		return
+	}
+
+	var entryRequest model.SetTopicACLEntryRequest
+	if err := c.ShouldBindJSON(&entryRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.Service.SetTopicACLEntry(c.Request.Context(), id, &entryRequest)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// DeleteTopicACLEntry godoc
+// @Summary Revoke access to a topic
+// @Description Remove an entry from the access control list of a topic. Removing the last entry opens the topic to everyone of the organization again. Requires admin access to the topic.
+// @Tags topics
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Topic ID"
+// @Param entryId path string true "ACL entry ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /topics/{id}/acl/{entryId} [delete]
+func (h *TopicHandler) DeleteTopicACLEntry(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID is required"})
+		return
+	}
+	entryID, err := strconv.ParseUint(c.Param("entryId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ACL entry ID format"})
+		return
+	}
+
+	if err := h.Service.DeleteTopicACLEntry(c.Request.Context(), id, uint(entryID)); err != nil {
+		handleErrorResponse(c, err)
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+// Verify godoc
+// @Summary Complete a two-step login
+// @Description Exchange the challenge token returned by /auth/login and a TOTP or recovery code for JWT tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.TwoFactorVerifyRequest true "Challenge token and code"
+// @Success 200 {object} model.LoginResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Router /auth/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var req model.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := h.twoFactorService.VerifyLogin(&req, clientInfo(c))
+	if err != nil {
+		status := http.StatusUnauthorized
+		switch err.Error() {
+		case "account temporarily locked", "too many failed login attempts":
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, model.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Setup godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and its otpauth:// provisioning URI to show as a QR code. Confirm with /auth/2fa/enable.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.TwoFactorSetupResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 401 {object} model.ErrorResponse
+// @Router /auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	response, err := h.twoFactorService.BeginEnrollment(c.GetUint("user_id"))
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Enable godoc
+// @Summary Enable two-factor authentication
+// @Description Confirm the enrollment with a code from the authenticator app. Returns recovery codes, which are only shown once. Tokens issued before the enrollment keep working.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} model.RecoveryCodesResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 401 {object} model.ErrorResponse
+// @Router /auth/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.twoFactorService.EnableTwoFactor(c.GetUint("user_id"), &req)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off. Not allowed while a role of the user requires it.
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param request body model.DisableTwoFactorRequest true "Password and TOTP or recovery code"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 401 {object} model.ErrorResponse
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req model.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.twoFactorService.DisableTwoFactor(c.GetUint("user_id"), &req); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes. The previous codes stop working.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} model.RecoveryCodesResponse
+// @Failure 400 {object} model.BadRequestError
+// @Failure 401 {object} model.ErrorResponse
+// @Router /auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.twoFactorService.RegenerateRecoveryCodes(c.GetUint("user_id"), &req)
+	if err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetUserTwoFactor godoc
+// @Summary Reset two-factor authentication of a user
+// @Description Remove the 2FA enrollment of a user who lost their authenticator and revoke their sessions (admin only)
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.BadRequestError
+// @Failure 404 {object} model.NotFoundError
+// @Failure 500 {object} model.InternalServerError
+// @Router /users/{id}/2fa/reset [post]
+func (h *TwoFactorHandler) ResetUserTwoFactor(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.ResetTwoFactor(id); err != nil {
+		handleErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterMailer writes mails to an io.Writer instead of delivering them.
+// It is meant for development machines and tests where no mail server is available.
+type WriterMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewWriterMailer(w io.Writer, from string) *WriterMailer {
+	return &WriterMailer{w: w, from: from}
+}
+
+func (m *WriterMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.w.Write(formatMessage(m.from, msg)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(m.w, "\r\n\r\n")
+	return err
+}
+
+// FileMailer appends mails to a file
+type FileMailer struct {
+	mu   sync.Mutex
+	path string
+	from string
+}
+
+func NewFileMailer(path string, from string) *FileMailer {
+	return &FileMailer{path: path, from: from}
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return NewWriterMailer(f, m.from).Send(msg)
+}
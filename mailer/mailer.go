@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+
+	"go-gin-gorm-backend/config"
+)
+
+// Message represents a plain text mail
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers mails
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewMailer creates the mailer selected by the MAIL_DRIVER configuration
+func NewMailer(cfg *config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "file":
+		return NewFileMailer(cfg.FilePath, cfg.From), nil
+	case "stdout":
+		return NewWriterMailer(os.Stdout, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
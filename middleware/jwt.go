@@ -2,16 +2,47 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
-	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates JWT token and sets user info in context
-func AuthMiddleware() gin.HandlerFunc {
+// passwordChangePath is the only route reachable while a password change is pending
+const passwordChangePath = "/auth/password"
+
+// twoFactorSetupPaths are the only routes reachable while a role requires 2FA the user has not enrolled in
+var twoFactorSetupPaths = []string{"/auth/2fa/setup", "/auth/2fa/enable"}
+
+// apiKeyHeader carries an API key for service-to-service calls
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyAuthKey marks requests authenticated with an API key in the gin context
+const apiKeyAuthKey = "api_key_auth"
+
+// AuthMiddleware validates the JWT token or API key, rejects revoked credentials and sets user info in context.
+// The teams of the user are resolved on every request, so team changes apply without a new token.
+func AuthMiddleware(tokenService *service.TokenService, apiKeyService *service.APIKeyService, teamService *service.TeamService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// API keys take precedence over bearer tokens
+		if apiKey := c.GetHeader(apiKeyHeader); apiKey != "" {
+			claims, err := apiKeyService.Authenticate(apiKey)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+				c.Abort()
+				return
+			}
+			if !checkPendingActions(c, claims) || !setClaimsWithGroups(c, claims, teamService) {
+				return
+			}
+			c.Set(apiKeyAuthKey, true)
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
@@ -30,33 +61,119 @@ func AuthMiddleware() gin.HandlerFunc {
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
 		// Validate the token
-		claims, err := config.ValidateToken(tokenString)
+		claims, err := tokenService.ValidateAccessToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
+		// A token issued before the user enrolled in 2FA still asks for the enrollment
+		if claims.TwoFactorSetup {
+			pending, err := tokenService.TwoFactorSetupPending(claims.UserID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+				c.Abort()
+				return
+			}
+			claims.TwoFactorSetup = pending
+		}
+
+		if !checkPendingActions(c, claims) {
+			return
+		}
+
 		// Set user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		if !setClaimsWithGroups(c, claims, teamService) {
+			return
+		}
 
 		c.Next()
 	}
 }
 
-// RoleMiddleware checks if user has required role
+// checkPendingActions blocks the request while the user still has to change their password or enroll
+// in the 2FA their role requires. It writes a 403 response and reports false when the request is blocked.
+func checkPendingActions(c *gin.Context, claims *model.Claims) bool {
+	// Block everything but the password change until the user has replaced their password
+	if claims.MustChangePassword && c.FullPath() != passwordChangePath {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Password change required"})
+		c.Abort()
+		return false
+	}
+
+	// Block everything but the enrollment until the user has set up the 2FA their role requires.
+	// A pending password change comes first.
+	if claims.TwoFactorSetup && !claims.MustChangePassword && !slices.Contains(twoFactorSetupPaths, c.FullPath()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication setup required"})
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// setClaimsWithGroups resolves the teams of the user and stores the user info in the context.
+// It writes a 500 response and reports false when the teams cannot be loaded.
+func setClaimsWithGroups(c *gin.Context, claims *model.Claims, teamService *service.TeamService) bool {
+	// OAuth client tokens have no user and therefore no teams
+	if claims.ClientID != "" {
+		setClaims(c, claims, nil)
+		return true
+	}
+
+	groups, err := teamService.ResolveGroups(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve teams"})
+		c.Abort()
+		return false
+	}
+	setClaims(c, claims, groups)
+	return true
+}
+
+// setClaims stores the authenticated user info in the context and attaches
+// the principal to the request context for the service layer.
+// The permissions granted through teams are added to those of the roles.
+func setClaims(c *gin.Context, claims *model.Claims, groups *model.UserGroups) {
+	permissions := claims.Permissions
+	var teamIDs []uint
+	if groups != nil {
+		permissions = groups.EffectivePermissions(claims.Permissions, strings.Fields(claims.Scope))
+		teamIDs = groups.TeamIDs
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("roles", claims.Roles)
+	c.Set("permissions", permissions)
+	c.Set("teams", teamIDs)
+	c.Set("claims", claims)
+
+	principal := model.Principal{
+		UserID:      claims.UserID,
+		Username:    claims.Username,
+		TenantID:    claims.TenantID,
+		TeamIDs:     teamIDs,
+		Permissions: permissions,
+		Client:      model.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()},
+	}
+	if claims.Act != nil {
+		principal.ActorUsername = claims.Act.Username
+	}
+	c.Request = c.Request.WithContext(model.WithPrincipal(c.Request.Context(), principal))
+}
+
+// RoleMiddleware checks if user has required role. Admins always pass.
 func RoleMiddleware(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("role")
+		roles, exists := c.Get("roles")
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User role not found"})
 			c.Abort()
 			return
 		}
 
-		if userRole != requiredRole && userRole != "admin" {
+		if !slices.Contains(roles.([]string), requiredRole) && !slices.Contains(roles.([]string), model.RoleAdmin) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
 			c.Abort()
 			return
@@ -68,12 +185,68 @@ func RoleMiddleware(requiredRole string) gin.HandlerFunc {
 
 // AdminMiddleware checks if user is admin
 func AdminMiddleware() gin.HandlerFunc {
-	return RoleMiddleware("admin")
+	return RoleMiddleware(model.RoleAdmin)
 }
 
-// OptionalAuthMiddleware validates JWT token if present, but doesn't require it
-func OptionalAuthMiddleware() gin.HandlerFunc {
+// RequirePermission checks if the effective permissions of the user include the required permission
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		permissions, exists := c.Get("permissions")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User permissions not found"})
+			c.Abort()
+			return
+		}
+
+		if !slices.Contains(permissions.([]string), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DenyImpersonation blocks a route for impersonation tokens. It guards actions only the user themselves
+// may take, such as changing credentials, and keeps admins from chaining impersonations.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := c.Get("claims"); ok && claims.(*model.Claims).Act != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed while impersonating"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DenyAPIKey blocks a route for requests authenticated with an API key, so a key can never be used
+// to create further credentials
+func DenyAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(apiKeyAuthKey) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed with an API key"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware validates JWT token or API key if present, but doesn't require it
+func OptionalAuthMiddleware(tokenService *service.TokenService, apiKeyService *service.APIKeyService, teamService *service.TeamService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(apiKeyHeader); apiKey != "" {
+			if claims, err := apiKeyService.Authenticate(apiKey); err == nil && setClaimsWithGroups(c, claims, teamService) {
+				c.Set(apiKeyAuthKey, true)
+			}
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
 			c.Next()
@@ -81,11 +254,9 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := config.ValidateToken(tokenString)
+		claims, err := tokenService.ValidateAccessToken(tokenString)
 		if err == nil {
-			c.Set("user_id", claims.UserID)
-			c.Set("username", claims.Username)
-			c.Set("role", claims.Role)
+			setClaimsWithGroups(c, claims, teamService)
 		}
 
 		c.Next()
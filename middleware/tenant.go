@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantUserMiddleware hides users of other organizations from routes with a user :id parameter.
+// Callers with the organizations:manage permission may reach users of every organization.
+func TenantUserMiddleware(userService *service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Malformed IDs are rejected by the handler
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil || slices.Contains(c.GetStringSlice("permissions"), model.PermissionOrganizationsManage) {
+			c.Next()
+			return
+		}
+
+		if err := userService.CheckUserInTenant(c.Request.Context(), uint(id)); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey represents a long-lived key for service-to-service access. Only the hash of the secret is stored;
+// the Prefix is stored in clear text so keys can be identified and looked up.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"not null;size:100"`
+	Prefix     string     `json:"prefix" gorm:"uniqueIndex;not null;size:16"`
+	KeyHash    string     `json:"-" gorm:"not null;size:64"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Scopes     string     `json:"-" gorm:"size:1000"` // space separated permission names, empty means all permissions of the owner
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ScopeList returns the scopes of the key
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// APIKeyResponse represents an API key without its secret
+type APIKeyResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	Name       string     `json:"name" example:"nightly-import"`
+	Prefix     string     `json:"prefix" example:"ggb_1a2b3c4d"`
+	UserID     uint       `json:"user_id" example:"2"`
+	Scopes     []string   `json:"scopes" example:"topics:read"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToAPIKeyResponse converts an APIKey to APIKeyResponse
+func (k *APIKey) ToAPIKeyResponse() APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		UserID:     k.UserID,
+		Scopes:     k.ScopeList(),
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+// CreateAPIKeyRequest represents a create API key request
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100" example:"nightly-import"`
+	Scopes    []string   `json:"scopes" example:"topics:read"`  // empty grants all permissions of the owner; required for keys of other users
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`          // optional expiry
+	UserID    *uint      `json:"user_id,omitempty" example:"2"` // owner, defaults to the caller (requires api_keys:manage for others)
+}
+
+// CreateAPIKeyResponse represents a newly created API key. The key is only returned once.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"ggb_1a2b3c4d_Zk3c9Jt0m8..."`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+// Actor identifies the admin behind an impersonation token (the "act" claim, RFC 8693 section 4.1)
+type Actor struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// Impersonation records an admin acting as another user. SessionID matches the sid claim of the
+// impersonation token, so ending the impersonation revokes the token.
+type Impersonation struct {
+	ID            uint       `json:"id" gorm:"primaryKey" example:"1"`
+	SessionID     string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	TenantID      uint       `json:"tenant_id" gorm:"not null;index" example:"1"`
+	ActorID       uint       `json:"actor_id" gorm:"not null;index" example:"1"`
+	ActorUsername string     `json:"actor_username" gorm:"not null;size:100" example:"admin"`
+	UserID        uint       `json:"user_id" gorm:"not null;index" example:"2"`
+	Username      string     `json:"username" gorm:"not null;size:100" example:"john_doe"`
+	Reason        string     `json:"reason" gorm:"size:500" example:"Ticket #4711: cannot edit topics"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	EndedBy       string     `json:"ended_by,omitempty" gorm:"size:210" example:"admin"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// StartImpersonationRequest represents a request to impersonate a user
+type StartImpersonationRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"Ticket #4711: cannot edit topics"`
+}
+
+// ImpersonationResponse contains the impersonation token. There is no refresh token; when it expires a new impersonation has to be started.
+type ImpersonationResponse struct {
+	Token         string        `json:"token"`
+	ExpiresAt     time.Time     `json:"expires_at"`
+	Impersonation Impersonation `json:"impersonation"`
+}
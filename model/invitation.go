@@ -0,0 +1,100 @@
+package model
+
+import (
+	"time"
+)
+
+// Invitation states
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// Invitation represents an admin inviting someone by email. The invitee opens a one-time link, chooses a
+// username and password, and gets an active account with the invited role. Only the hash of the token is stored.
+type Invitation struct {
+	ID         uint       `json:"id" gorm:"primaryKey" example:"1"`
+	TenantID   uint       `json:"tenant_id" gorm:"not null;index" example:"1"` // organization the invitee joins
+	Email      string     `json:"email" gorm:"not null;size:255;index" example:"jane@example.com"`
+	FullName   string     `json:"full_name" gorm:"size:255" example:"Jane Doe"` // suggested name, the invitee may change it
+	RoleID     uint       `json:"role_id" gorm:"not null" example:"2"`
+	Role       Role       `json:"role"`
+	TeamID     *uint      `json:"team_id,omitempty" example:"3"` // team the invitee joins
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	UserID     *uint      `json:"user_id,omitempty" example:"5"` // account created when the invitation was accepted
+	InvitedBy  string     `json:"invited_by" gorm:"size:210" example:"admin"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Status returns the state of the invitation at now
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+// InvitationResponse represents an invitation with its current state
+type InvitationResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	TenantID   uint       `json:"tenant_id" example:"1"`
+	Email      string     `json:"email" example:"jane@example.com"`
+	FullName   string     `json:"full_name" example:"Jane Doe"`
+	Role       string     `json:"role" example:"user"`
+	TeamID     *uint      `json:"team_id,omitempty" example:"3"`
+	Status     string     `json:"status" example:"pending"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	UserID     *uint      `json:"user_id,omitempty" example:"5"`
+	InvitedBy  string     `json:"invited_by" example:"admin"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToInvitationResponse converts an Invitation to InvitationResponse
+func (i *Invitation) ToInvitationResponse() InvitationResponse {
+	return InvitationResponse{
+		ID:         i.ID,
+		TenantID:   i.TenantID,
+		Email:      i.Email,
+		FullName:   i.FullName,
+		Role:       i.Role.Name,
+		TeamID:     i.TeamID,
+		Status:     i.Status(time.Now()),
+		ExpiresAt:  i.ExpiresAt,
+		AcceptedAt: i.AcceptedAt,
+		RevokedAt:  i.RevokedAt,
+		UserID:     i.UserID,
+		InvitedBy:  i.InvitedBy,
+		CreatedAt:  i.CreatedAt,
+	}
+}
+
+// CreateInvitationRequest represents an admin request to invite someone by email
+type CreateInvitationRequest struct {
+	Email    string `json:"email" binding:"required,email,max=255" example:"jane@example.com"`
+	FullName string `json:"full_name" binding:"max=255" example:"Jane Doe"`
+	Role     string `json:"role" example:"user"`             // defaults to "user"
+	TenantID *uint  `json:"tenant_id,omitempty" example:"1"` // defaults to the organization of the admin; other organizations need organizations:manage
+	TeamID   *uint  `json:"team_id,omitempty" example:"3"`   // team of the admin's organization the invitee joins
+}
+
+// AcceptInvitationRequest represents the invitee accepting an invitation
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required" example:"Zk3c9Jt0m8..."`
+	Username string `json:"username" binding:"required,max=100" example:"jane_doe"`
+	Password string `json:"password" binding:"required,min=8" example:"N3wStr0ngPassw0rd"`
+	FullName string `json:"full_name" binding:"max=255" example:"Jane Doe"` // defaults to the name given in the invitation
+}
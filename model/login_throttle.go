@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// LoginThrottle tracks failed login attempts for a username ("user:<name>") or a client IP ("ip:<addr>")
+type LoginThrottle struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Key            string     `json:"key" gorm:"uniqueIndex;not null;size:255"`
+	FailedAttempts int        `json:"failed_attempts" gorm:"not null;default:0"`
+	LastFailedAt   time.Time  `json:"last_failed_at"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
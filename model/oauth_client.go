@@ -0,0 +1,92 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// GrantTypeClientCredentials is the only OAuth2 grant supported at /oauth/token
+const GrantTypeClientCredentials = "client_credentials"
+
+// OAuthClient represents a partner system that obtains access tokens with the client credentials grant.
+// Only the hash of the secret is stored.
+type OAuthClient struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ClientID   string     `json:"client_id" gorm:"uniqueIndex;not null;size:64"`
+	Name       string     `json:"name" gorm:"not null;size:100"`
+	SecretHash string     `json:"-" gorm:"not null;size:64"`
+	Scopes     string     `json:"-" gorm:"not null;size:1000"` // space separated permission names the client may request
+	TenantID   uint       `json:"tenant_id" gorm:"not null;index"`
+	CreatedBy  string     `json:"created_by" gorm:"size:210"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ScopeList returns the scopes the client may request
+func (c *OAuthClient) ScopeList() []string {
+	return strings.Fields(c.Scopes)
+}
+
+// OAuthClientResponse represents an OAuth client without its secret
+type OAuthClientResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	ClientID   string     `json:"client_id" example:"ggc_1a2b3c4d5e6f7a8b"`
+	Name       string     `json:"name" example:"pharmacy-sync"`
+	Scopes     []string   `json:"scopes" example:"topics:read"`
+	TenantID   uint       `json:"tenant_id" example:"1"`
+	CreatedBy  string     `json:"created_by" example:"admin"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToOAuthClientResponse converts an OAuthClient to OAuthClientResponse
+func (c *OAuthClient) ToOAuthClientResponse() OAuthClientResponse {
+	return OAuthClientResponse{
+		ID:         c.ID,
+		ClientID:   c.ClientID,
+		Name:       c.Name,
+		Scopes:     c.ScopeList(),
+		TenantID:   c.TenantID,
+		CreatedBy:  c.CreatedBy,
+		LastUsedAt: c.LastUsedAt,
+		RevokedAt:  c.RevokedAt,
+		CreatedAt:  c.CreatedAt,
+	}
+}
+
+// CreateOAuthClientRequest represents a register OAuth client request
+type CreateOAuthClientRequest struct {
+	Name   string   `json:"name" binding:"required,max=100" example:"pharmacy-sync"`
+	Scopes []string `json:"scopes" binding:"required,min=1" example:"topics:read"`
+}
+
+// CreateOAuthClientResponse contains the client secret. It is only returned once, when the client is registered.
+type CreateOAuthClientResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:"client_secret" example:"0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0"`
+}
+
+// OAuthTokenRequest represents a token request (RFC 6749 section 4.4).
+// The client credentials may also be sent with HTTP Basic authentication.
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" example:"client_credentials"`
+	ClientID     string `form:"client_id" example:"ggc_1a2b3c4d5e6f7a8b"`
+	ClientSecret string `form:"client_secret"`
+	Scope        string `form:"scope" example:"topics:read"` // space separated, defaults to all scopes of the client
+}
+
+// OAuthTokenResponse represents a successful token response
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int    `json:"expires_in" example:"3600"`
+	Scope       string `json:"scope" example:"topics:read"`
+}
+
+// OAuthErrorResponse represents an error response of the token endpoint (RFC 6749 section 5.2)
+type OAuthErrorResponse struct {
+	Error            string `json:"error" example:"invalid_client"`
+	ErrorDescription string `json:"error_description,omitempty" example:"client authentication failed"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// UserIdentity links a local user to an account at an external OpenID Connect provider
+type UserIdentity struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Issuer      string     `json:"issuer" gorm:"not null;size:255;uniqueIndex:idx_user_identity_issuer_subject"`
+	Subject     string     `json:"subject" gorm:"not null;size:255;uniqueIndex:idx_user_identity_issuer_subject"`
+	Email       string     `json:"email" gorm:"size:255"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OIDCAuthRequest is a pending authorization code login. Only the hash of the state is stored.
+type OIDCAuthRequest struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	StateHash    string    `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	Nonce        string    `json:"-" gorm:"not null;size:64"`
+	CodeVerifier string    `json:"-" gorm:"not null;size:128"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
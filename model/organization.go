@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+)
+
+// DefaultOrganizationSlug identifies the organization seeded on startup
+const DefaultOrganizationSlug = "default"
+
+// Organization represents a tenant. Users, topics and topic details belong to exactly one organization
+// and are only visible within it.
+type Organization struct {
+	ID        uint      `json:"id" gorm:"primaryKey" example:"1"`
+	Name      string    `json:"name" gorm:"uniqueIndex;not null;size:255" example:"Sukhumvit Clinic"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null;size:100" example:"sukhumvit-clinic"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateOrganizationRequest represents a create organization request
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255" example:"Sukhumvit Clinic"`
+	Slug string `json:"slug" binding:"required,max=100" example:"sukhumvit-clinic"` // lowercase letters, digits and dashes
+}
+
+// UpdateOrganizationRequest represents an update organization request (with optional fields)
+type UpdateOrganizationRequest struct {
+	Name *string `json:"name,omitempty" binding:"omitempty,max=255" example:"Sukhumvit Clinic"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// PasswordResetToken represents a one-time password reset token. Only the hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ForgotPasswordRequest represents the forgot password request structure
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"john@example.com"`
+}
+
+// ResetPasswordRequest represents the reset password request structure
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required" example:"Zk3c9Jt0m8..."`
+	NewPassword string `json:"new_password" binding:"required,min=8" example:"N3wStr0ngPassw0rd"`
+}
+
+// MessageResponse represents a simple informational response
+type MessageResponse struct {
+	Message string `json:"message" example:"Operation completed"`
+}
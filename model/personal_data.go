@@ -0,0 +1,9 @@
+package model
+
+// ErasedUserFullName replaces the name of a user whose personal data has been erased
+const ErasedUserFullName = "Deleted User"
+
+// EraseAccountRequest represents the request of a user to erase their own account
+type EraseAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"N3wStr0ngPassw0rd"` // confirms the request
+}
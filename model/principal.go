@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+	"slices"
+)
+
+// SystemActor is recorded when a change is not made on behalf of an authenticated user
+const SystemActor = "system"
+
+// Principal identifies the authenticated user a request acts for
+type Principal struct {
+	UserID        uint
+	Username      string
+	TenantID      uint
+	ActorUsername string   // admin impersonating the user, if any
+	TeamIDs       []uint   // teams the user belongs to
+	Permissions   []string // effective permissions of roles and teams
+	Client        ClientInfo
+}
+
+// InTeam reports whether the principal belongs to the team
+func (p Principal) InTeam(teamID uint) bool {
+	return slices.Contains(p.TeamIDs, teamID)
+}
+
+// HasPermission reports whether the effective permissions of the principal include permission
+func (p Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// ActorFromContext returns the name recorded in CreatedBy/UpdatedBy for changes made with ctx.
+// Changes made while impersonating record both identities, e.g. "john_doe (via admin)".
+// With usernames of up to 100 characters that is up to 207 characters, so actor columns are sized 210.
+func ActorFromContext(ctx context.Context) string {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.Username == "" {
+		return SystemActor
+	}
+	if principal.ActorUsername != "" {
+		return principal.Username + " (via " + principal.ActorUsername + ")"
+	}
+	return principal.Username
+}
+
+// TenantFromContext returns the organization data access with ctx is scoped to.
+// Without a principal it returns 0, which matches no rows.
+func TenantFromContext(ctx context.Context) uint {
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		return principal.TenantID
+	}
+	return 0
+}
+
+// ClientFromContext returns the client the request with ctx came from
+func ClientFromContext(ctx context.Context) ClientInfo {
+	principal, _ := PrincipalFromContext(ctx)
+	return principal.Client
+}
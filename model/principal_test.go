@@ -0,0 +1,83 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestActorFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{
+			name: "no principal",
+			ctx:  context.Background(),
+			want: SystemActor,
+		},
+		{
+			name: "principal without a user, such as an OAuth client",
+			ctx:  WithPrincipal(context.Background(), Principal{TenantID: 1}),
+			want: SystemActor,
+		},
+		{
+			name: "user",
+			ctx:  WithPrincipal(context.Background(), Principal{UserID: 2, Username: "john_doe", TenantID: 1}),
+			want: "john_doe",
+		},
+		{
+			name: "admin impersonating a user",
+			ctx:  WithPrincipal(context.Background(), Principal{UserID: 2, Username: "john_doe", TenantID: 1, ActorUsername: "admin"}),
+			want: "john_doe (via admin)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ActorFromContext(tt.ctx); got != tt.want {
+				t.Errorf("ActorFromContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActorFitsColumns(t *testing.T) {
+	longName := strings.Repeat("a", 100)
+	actor := ActorFromContext(WithPrincipal(context.Background(), Principal{UserID: 2, Username: longName, TenantID: 1, ActorUsername: longName}))
+
+	tests := []struct {
+		model  interface{}
+		fields []string
+	}{
+		{model: &Topic{}, fields: []string{"CreatedBy", "UpdatedBy"}},
+		{model: &TopicDetail{}, fields: []string{"CreatedBy", "UpdatedBy"}},
+		{model: &OAuthClient{}, fields: []string{"CreatedBy"}},
+		{model: &Impersonation{}, fields: []string{"EndedBy"}},
+		{model: &Invitation{}, fields: []string{"InvitedBy"}},
+		{model: &SecurityEvent{}, fields: []string{"Actor"}},
+		{model: &TopicACLEntry{}, fields: []string{"CreatedBy", "UpdatedBy"}},
+	}
+
+	for _, tt := range tests {
+		s, err := schema.Parse(tt.model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range tt.fields {
+			t.Run(s.Name+"."+name, func(t *testing.T) {
+				field := s.LookUpField(name)
+				if field == nil {
+					t.Fatalf("no field %s", name)
+				}
+				if field.Size < len(actor) {
+					t.Errorf("column size %d, impersonated changes by users with long names need %d", field.Size, len(actor))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package model
+
+// UserPreferences holds settings the frontend keeps per user
+type UserPreferences struct {
+	Language string `json:"language,omitempty" example:"th"`
+	Timezone string `json:"timezone,omitempty" example:"Asia/Bangkok"`
+	Theme    string `json:"theme,omitempty" example:"dark"`
+}
+
+// UpdateProfileRequest represents a request of the current user to update their own profile (with optional fields).
+// A new email address only replaces the current one after it has been verified.
+type UpdateProfileRequest struct {
+	FullName    *string                   `json:"full_name,omitempty" binding:"omitempty,min=1,max=255" example:"John Doe"`
+	Email       *string                   `json:"email,omitempty" binding:"omitempty,email,max=255" example:"john.doe@example.com"`
+	Preferences *UpdatePreferencesRequest `json:"preferences,omitempty"`
+}
+
+// UpdatePreferencesRequest represents a change of preferences (with optional fields, an empty string clears a preference)
+type UpdatePreferencesRequest struct {
+	Language *string `json:"language,omitempty" binding:"omitempty,max=10" example:"th"`
+	Timezone *string `json:"timezone,omitempty" binding:"omitempty,max=64" example:"Asia/Bangkok"`
+	Theme    *string `json:"theme,omitempty" binding:"omitempty,oneof=light dark system" example:"dark"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken represents a server-side stored refresh token.
+// Tokens issued from the same login share a FamilyID so the whole chain can be revoked at once.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	FamilyID  string     `json:"family_id" gorm:"not null;index;size:64"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`    // set when the token has been rotated
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // set when the token family has been revoked
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RefreshTokenRequest represents the refresh token request structure
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"q1rV7yQ2m0b3a9..."`
+}
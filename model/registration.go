@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+// Registration states of self-registered users that cannot log in yet
+const (
+	RegistrationPendingVerification = "pending_verification"
+	RegistrationPendingApproval     = "pending_approval"
+)
+
+// EmailVerificationToken represents a one-time link that confirms an email address. Only the hash of the token is stored.
+type EmailVerificationToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Email     string     `json:"email" gorm:"not null;size:255"` // the address the link was mailed to
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RegisterRequest represents the self-registration request structure
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,max=100" example:"john_doe"`
+	Email    string `json:"email" binding:"required,email,max=255" example:"john@example.com"`
+	Password string `json:"password" binding:"required,min=8" example:"N3wStr0ngPassw0rd"`
+	FullName string `json:"full_name" binding:"required,max=255" example:"John Doe"`
+}
+
+// RegisterResponse represents the self-registration response structure
+type RegisterResponse struct {
+	User    UserResponse `json:"user"`
+	Message string       `json:"message" example:"Check your inbox to verify your email address"`
+}
+
+// VerifyEmailRequest represents the email verification request structure
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"Zk3c9Jt0m8..."`
+}
+
+// ResendVerificationRequest represents the request to mail a new verification link
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email" example:"john@example.com"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+)
+
+// Built-in role names
+const (
+	RoleAdmin  = "admin"
+	RoleUser   = "user"
+	RoleViewer = "viewer"
+	// RolePlatformAdmin holds organizations:manage, which the admin role of an organization does not get
+	RolePlatformAdmin = "platform_admin"
+)
+
+// Built-in permission names
+const (
+	PermissionTopicsRead    = "topics:read"
+	PermissionTopicsWrite   = "topics:write"
+	PermissionUsersManage   = "users:manage"
+	PermissionAPIKeysManage = "api_keys:manage"
+	// PermissionTopicsManage allows editing topics regardless of the team owning them
+	PermissionTopicsManage = "topics:manage"
+	// PermissionOrganizationsManage spans tenants: it allows managing organizations and placing users in any of them
+	PermissionOrganizationsManage = "organizations:manage"
+)
+
+// Permission represents a single grantable permission such as "topics:write"
+type Permission struct {
+	ID          uint   `json:"id" gorm:"primaryKey" example:"1"`
+	Name        string `json:"name" gorm:"uniqueIndex;not null;size:100" example:"topics:write"`
+	Description string `json:"description" gorm:"size:255" example:"Create, update and delete topics and topic details"`
+}
+
+// Role represents a named set of permissions that can be assigned to users
+type Role struct {
+	ID               uint         `json:"id" gorm:"primaryKey" example:"1"`
+	Name             string       `json:"name" gorm:"uniqueIndex;not null;size:50" example:"viewer"`
+	Description      string       `json:"description" gorm:"size:255" example:"Read-only access to topics"`
+	Permissions      []Permission `json:"permissions" gorm:"many2many:role_permissions"`
+	RequireTwoFactor bool         `json:"require_two_factor" gorm:"default:false" example:"false"` // members must enroll in two-factor authentication
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// CreateRoleRequest represents a create role request
+type CreateRoleRequest struct {
+	Name             string   `json:"name" binding:"required,max=50" example:"viewer"`
+	Description      string   `json:"description" binding:"max=255" example:"Read-only access to topics"`
+	Permissions      []string `json:"permissions" example:"topics:read"`
+	RequireTwoFactor bool     `json:"require_two_factor" example:"false"`
+}
+
+// UpdateRoleRequest represents an update role request (with optional fields)
+type UpdateRoleRequest struct {
+	Description      *string   `json:"description,omitempty" binding:"omitempty,max=255" example:"Read-only access to topics"`
+	Permissions      *[]string `json:"permissions,omitempty" example:"topics:read"`
+	RequireTwoFactor *bool     `json:"require_two_factor,omitempty" example:"true"`
+}
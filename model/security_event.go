@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+)
+
+// Security event types
+const (
+	SecurityEventLogin          = "login"           // password login at /auth/login
+	SecurityEventLoginTwoFactor = "login_2fa"       // second step of a login with two-factor authentication
+	SecurityEventLoginOIDC      = "login_oidc"      // single sign-on login
+	SecurityEventTokenRefresh   = "token_refresh"   // refresh token exchanged for a new token pair
+	SecurityEventLogout         = "logout"          // session ended by the user
+	SecurityEventPasswordChange = "password_change" // password changed by the user
+	SecurityEventPasswordReset  = "password_reset"  // password set with a reset link
+	SecurityEventRoleChange     = "role_change"     // roles of a user replaced by an admin
+)
+
+// Reasons recorded with failed security events
+const (
+	SecurityReasonUnknownUser       = "unknown_user"
+	SecurityReasonInvalidPassword   = "invalid_password"
+	SecurityReasonServiceAccount    = "service_account"
+	SecurityReasonInactiveAccount   = "inactive_account"
+	SecurityReasonLockedOut         = "locked_out"
+	SecurityReasonInvalidCode       = "invalid_two_factor_code"
+	SecurityReasonInvalidToken      = "invalid_token"
+	SecurityReasonRevokedToken      = "revoked_token"
+	SecurityReasonExpiredToken      = "expired_token"
+	SecurityReasonRefreshTokenReuse = "refresh_token_reuse"
+	SecurityReasonSSOFailed         = "sso_failed"
+)
+
+// SecurityReasonTwoFactorPending is recorded with a successful password check that still needs a two-factor code
+const SecurityReasonTwoFactorPending = "two_factor_pending"
+
+// SecurityEvent records an authentication attempt or a security relevant change of an account.
+// Username holds the name that was tried even when no such user exists.
+type SecurityEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey" example:"1"`
+	TenantID  uint      `json:"tenant_id" gorm:"not null;index" example:"1"` // 0 when the user is unknown
+	Type      string    `json:"type" gorm:"not null;size:50;index" example:"login"`
+	Success   bool      `json:"success" example:"false"`
+	Reason    string    `json:"reason,omitempty" gorm:"size:100" example:"invalid_password"`
+	UserID    *uint     `json:"user_id,omitempty" gorm:"index" example:"2"`
+	Username  string    `json:"username" gorm:"size:100;index" example:"john_doe"`
+	Actor     string    `json:"actor,omitempty" gorm:"size:210" example:"admin"` // who made the change when it was not the user
+	Details   string    `json:"details,omitempty" gorm:"size:1000" example:"user -> viewer"`
+	IPAddress string    `json:"ip_address" gorm:"size:64;index" example:"203.0.113.10"`
+	UserAgent string    `json:"user_agent" gorm:"size:512" example:"Mozilla/5.0"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// SecurityEventQuery filters the security event log. All filters are optional.
+type SecurityEventQuery struct {
+	Type      string     `form:"type" example:"login"`
+	UserID    uint       `form:"user_id" example:"2"`
+	Username  string     `form:"username" example:"john_doe"`
+	IPAddress string     `form:"ip_address" example:"203.0.113.10"`
+	Success   *bool      `form:"success" example:"false"`
+	Since     *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-01T00:00:00Z"`
+	Until     *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-31T23:59:59Z"`
+	Limit     int        `form:"limit" binding:"omitempty,min=1,max=1000" example:"100"` // defaults to 100
+	Offset    int        `form:"offset" binding:"omitempty,min=0" example:"0"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// ClientInfo describes the client a request came from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Session represents one signed-in device. It lives as long as its refresh token family,
+// and SessionID matches the sid claim of every access token issued within it.
+type Session struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SessionID      string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	UserAgent      string     `json:"user_agent" gorm:"size:512"`
+	IPAddress      string     `json:"ip_address" gorm:"size:64"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// SessionResponse represents an active session
+type SessionResponse struct {
+	ID             uint      `json:"id" example:"1"`
+	Device         string    `json:"device" example:"Chrome on Windows"`
+	UserAgent      string    `json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36"`
+	IPAddress      string    `json:"ip_address" example:"203.0.113.10"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Current        bool      `json:"current" example:"true"` // the session the request was made with
+}
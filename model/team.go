@@ -0,0 +1,112 @@
+package model
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Team represents a group of users of one organization. Members get the permissions of the team
+// on top of those of their roles, and a team can own topics.
+type Team struct {
+	ID          uint         `json:"id" gorm:"primaryKey" example:"1"`
+	TenantID    uint         `json:"tenant_id" gorm:"not null;uniqueIndex:idx_teams_tenant_name" example:"1"`
+	Name        string       `json:"name" gorm:"not null;size:100;uniqueIndex:idx_teams_tenant_name" example:"pharmacy"`
+	Description string       `json:"description" gorm:"size:255" example:"Pharmacy department"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:team_permissions"`
+	Members     []User       `json:"-" gorm:"many2many:team_members"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// TeamMember represents a member in a team response
+type TeamMember struct {
+	ID       uint   `json:"id" example:"2"`
+	Username string `json:"username" example:"john_doe"`
+	FullName string `json:"full_name" example:"John Doe"`
+}
+
+// TeamResponse represents a team with its permission names and members
+type TeamResponse struct {
+	ID          uint         `json:"id" example:"1"`
+	TenantID    uint         `json:"tenant_id" example:"1"`
+	Name        string       `json:"name" example:"pharmacy"`
+	Description string       `json:"description" example:"Pharmacy department"`
+	Permissions []string     `json:"permissions" example:"topics:write"`
+	Members     []TeamMember `json:"members"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// ToTeamResponse converts a Team to TeamResponse
+func (t *Team) ToTeamResponse() TeamResponse {
+	permissions := make([]string, 0, len(t.Permissions))
+	for _, permission := range t.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+	members := make([]TeamMember, 0, len(t.Members))
+	for _, member := range t.Members {
+		members = append(members, TeamMember{ID: member.ID, Username: member.Username, FullName: member.FullName})
+	}
+
+	return TeamResponse{
+		ID:          t.ID,
+		TenantID:    t.TenantID,
+		Name:        t.Name,
+		Description: t.Description,
+		Permissions: permissions,
+		Members:     members,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// CreateTeamRequest represents a create team request
+type CreateTeamRequest struct {
+	Name        string   `json:"name" binding:"required,max=100" example:"pharmacy"`
+	Description string   `json:"description" binding:"max=255" example:"Pharmacy department"`
+	Permissions []string `json:"permissions" example:"topics:write"`
+}
+
+// UpdateTeamRequest represents an update team request (with optional fields)
+type UpdateTeamRequest struct {
+	Name        *string   `json:"name,omitempty" binding:"omitempty,max=100" example:"pharmacy"`
+	Description *string   `json:"description,omitempty" binding:"omitempty,max=255" example:"Pharmacy department"`
+	Permissions *[]string `json:"permissions,omitempty" example:"topics:write"`
+}
+
+// UserGroups holds the teams a user belongs to and the permissions granted through them
+type UserGroups struct {
+	TeamIDs     []uint
+	Permissions []string
+}
+
+// NewUserGroups collects the team IDs and the union of the team permissions
+func NewUserGroups(teams []Team) *UserGroups {
+	groups := &UserGroups{TeamIDs: make([]uint, 0, len(teams)), Permissions: []string{}}
+	for _, team := range teams {
+		groups.TeamIDs = append(groups.TeamIDs, team.ID)
+		for _, permission := range team.Permissions {
+			if !slices.Contains(groups.Permissions, permission.Name) {
+				groups.Permissions = append(groups.Permissions, permission.Name)
+			}
+		}
+	}
+	return groups
+}
+
+// EffectivePermissions adds the team permissions to the permissions of the roles.
+// When the credential is limited to scopes, team permissions outside of them are left out.
+func (g *UserGroups) EffectivePermissions(rolePermissions, scopes []string) []string {
+	permissions := slices.Clone(rolePermissions)
+	for _, permission := range g.Permissions {
+		if len(scopes) > 0 && !slices.Contains(scopes, permission) {
+			continue
+		}
+		if !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
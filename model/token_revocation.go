@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+// RevokedToken represents a revoked access token identified by its jti claim
+type RevokedToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TokenID   string    `json:"token_id" gorm:"uniqueIndex;not null;size:64"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"` // the row can be purged once the token itself has expired
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserTokenRevocation records that every token issued to a user before RevokedBefore is revoked
+type UserTokenRevocation struct {
+	UserID        uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	RevokedBefore time.Time `json:"revoked_before" gorm:"not null"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -7,22 +7,26 @@ import (
 // Topic represents a topic entity
 // @Description Topic entity
 type Topic struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id" example:"1"`
-	Name      string    `gorm:"size:255;not null;uniqueIndex" json:"name" example:"ยา" binding:"required"` // ชื่อ topic
-	Order     int       `gorm:"not null" json:"order" example:"1" binding:"required"`                      // ลำดับ topic
-	CreatedBy string    `gorm:"size:100;not null" json:"created_by" example:"admin" binding:"required"`    // ผู้สร้าง
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at,omitempty" example:"2024-01-01T00:00:00Z"` // วันที่สร้าง
-	UpdatedBy string    `gorm:"size:100" json:"updated_by" example:"admin"`                                // ผู้อัพเดท
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"` // วันที่อัพเดท
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id" example:"1"`
+	TenantID    uint      `gorm:"not null;uniqueIndex:idx_topics_tenant_name" json:"tenant_id" example:"1"`                         // รหัสองค์กร
+	Name        string    `gorm:"size:255;not null;uniqueIndex:idx_topics_tenant_name" json:"name" example:"ยา" binding:"required"` // ชื่อ topic (ไม่ซ้ำภายในองค์กร)
+	Order       int       `gorm:"not null" json:"order" example:"1" binding:"required"`                                             // ลำดับ topic
+	OwnerTeamID *uint     `gorm:"index" json:"owner_team_id,omitempty" example:"1"`                                                 // ทีมเจ้าของ topic (แก้ไขได้เฉพาะสมาชิกทีม)
+	CreatedBy   string    `gorm:"size:210;not null" json:"created_by" example:"admin" binding:"required"`                           // ผู้สร้าง
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`                        // วันที่สร้าง
+	UpdatedBy   string    `gorm:"size:210" json:"updated_by" example:"admin"`                                                       // ผู้อัพเดท
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`                        // วันที่อัพเดท
 }
 
 // TopicRequest represents a topic request (without auto-generated fields)
 // @Description Topic request object
 type CreateTopicRequest struct {
-	Name string `json:"name" example:"ยา" binding:"required"` // ชื่อ topic
+	Name        string `json:"name" example:"ยา" binding:"required"` // ชื่อ topic
+	OwnerTeamID *uint  `json:"owner_team_id,omitempty" example:"1"`  // ทีมเจ้าของ topic (optional)
 }
 
 type UpdateTopicRequest struct {
-	Name  *string `json:"name,omitempty" example:"ยา"` // ชื่อ topic (optional)
-	Order *int    `json:"order,omitempty" example:"1"` // ลำดับ topic (optional)
+	Name        *string `json:"name,omitempty" example:"ยา"`         // ชื่อ topic (optional)
+	Order       *int    `json:"order,omitempty" example:"1"`         // ลำดับ topic (optional)
+	OwnerTeamID *uint   `json:"owner_team_id,omitempty" example:"1"` // ทีมเจ้าของ topic (optional, 0 = ไม่มีทีมเจ้าของ)
 }
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+)
+
+// Levels of access to a topic. Each level includes the ones before it.
+const (
+	TopicAccessRead  = "read"  // see the topic and its details
+	TopicAccessWrite = "write" // edit the topic and its details
+	TopicAccessAdmin = "admin" // delete the topic, change its owning team and its access control list
+)
+
+// Subjects a topic ACL entry can grant access to
+const (
+	TopicACLSubjectUser = "user"
+	TopicACLSubjectTeam = "team"
+)
+
+var topicAccessRanks = map[string]int{TopicAccessRead: 1, TopicAccessWrite: 2, TopicAccessAdmin: 3}
+
+// TopicAccessAllows reports whether the granted access level includes the required one.
+// An empty granted level means no access.
+func TopicAccessAllows(granted, required string) bool {
+	return granted != "" && topicAccessRanks[granted] >= topicAccessRanks[required]
+}
+
+// TopicACLEntry grants a user or a team access to a topic.
+// A topic without entries is open to everyone of the organization.
+type TopicACLEntry struct {
+	ID          uint      `json:"id" gorm:"primaryKey" example:"1"`
+	TenantID    uint      `json:"tenant_id" gorm:"not null;index" example:"1"`
+	TopicID     uint      `json:"topic_id" gorm:"not null;uniqueIndex:idx_topic_acl_subject" example:"1"`
+	SubjectType string    `json:"subject_type" gorm:"not null;size:10;uniqueIndex:idx_topic_acl_subject" example:"team"`
+	SubjectID   uint      `json:"subject_id" gorm:"not null;uniqueIndex:idx_topic_acl_subject" example:"2"`
+	Access      string    `json:"access" gorm:"not null;size:10" example:"read"`
+	CreatedBy   string    `json:"created_by" gorm:"size:210;not null" example:"admin"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedBy   string    `json:"updated_by" gorm:"size:210" example:"admin"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AppliesTo reports whether the entry grants access to the principal
+func (e TopicACLEntry) AppliesTo(principal Principal) bool {
+	switch e.SubjectType {
+	case TopicACLSubjectUser:
+		return principal.UserID != 0 && e.SubjectID == principal.UserID
+	case TopicACLSubjectTeam:
+		return principal.InTeam(e.SubjectID)
+	}
+	return false
+}
+
+// SetTopicACLEntryRequest grants a user or a team access to a topic, replacing any access they had
+type SetTopicACLEntryRequest struct {
+	SubjectType string `json:"subject_type" binding:"required,oneof=user team" example:"team"`
+	SubjectID   uint   `json:"subject_id" binding:"required" example:"2"`
+	Access      string `json:"access" binding:"required,oneof=read write admin" example:"read"`
+}
@@ -8,13 +8,14 @@ import (
 // @Description Topic detail entity
 type TopicDetail struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id" example:"1"`
-	TopicID   uint      `gorm:"not null;index" json:"topic_id" example:"1"`                                      // รหัส topic
-	Name      string    `gorm:"size:255;not null;uniqueIndex" json:"name" example:"ยาแก้ปวด" binding:"required"` // ชื่อ topic_detail
-	Order     int       `gorm:"not null" json:"order" example:"1" binding:"required"`                            // ลำดับ topic_detail
-	CreatedBy string    `gorm:"size:100;not null" json:"created_by" example:"admin" binding:"required"`          // ผู้สร้าง
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`       // วันที่สร้าง
-	UpdatedBy string    `gorm:"size:100" json:"updated_by" example:"admin"`                                      // ผู้อัพเดท
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`       // วันที่อัพเดท
+	TenantID  uint      `gorm:"not null;uniqueIndex:idx_topic_details_tenant_name" json:"tenant_id" example:"1"`                               // รหัสองค์กร
+	TopicID   uint      `gorm:"not null;index" json:"topic_id" example:"1"`                                                                    // รหัส topic
+	Name      string    `gorm:"size:255;not null;uniqueIndex:idx_topic_details_tenant_name" json:"name" example:"ยาแก้ปวด" binding:"required"` // ชื่อ topic_detail (ไม่ซ้ำภายในองค์กร)
+	Order     int       `gorm:"not null" json:"order" example:"1" binding:"required"`                                                          // ลำดับ topic_detail
+	CreatedBy string    `gorm:"size:210;not null" json:"created_by" example:"admin" binding:"required"`                                        // ผู้สร้าง
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`                                     // วันที่สร้าง
+	UpdatedBy string    `gorm:"size:210" json:"updated_by" example:"admin"`                                                                    // ผู้อัพเดท
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`                                     // วันที่อัพเดท
 	Topic     Topic     `gorm:"foreignKey:TopicID" json:"topic,omitempty"`
 }
 
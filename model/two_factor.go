@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+// TokenPurposeTwoFactorChallenge marks the short-lived token returned by a password login when 2FA is enabled
+const TokenPurposeTwoFactorChallenge = "2fa_challenge"
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the authenticator is lost.
+// Only the hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null;size:64;index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TwoFactorSetupResponse carries the secret of a new enrollment. ProvisioningURI is rendered as a QR code.
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/Go%20Gin%20GORM%20Backend:admin?algorithm=SHA1&digits=6&issuer=Go+Gin+GORM+Backend&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+// TwoFactorCodeRequest confirms an action with a TOTP code or a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// DisableTwoFactorRequest represents a request to turn two-factor authentication off
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required" example:"N3wStr0ngPassw0rd"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+// TwoFactorVerifyRequest completes a two-step login
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required" example:"123456"` // TOTP code or recovery code
+}
+
+// RecoveryCodesResponse lists newly generated recovery codes. They are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3vd-9xq2"`
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,61 +10,159 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Username  string         `json:"username" gorm:"uniqueIndex;not null;size:100" example:"john_doe"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null;size:255" example:"john@example.com"`
-	Password  string         `json:"-" gorm:"not null;size:255"` // "-" means this field won't be included in JSON
-	FullName  string         `json:"full_name" gorm:"not null;size:255" example:"John Doe"`
-	Role      string         `json:"role" gorm:"default:'user';size:50" example:"user"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID                 uint            `json:"id" gorm:"primaryKey"`
+	Username           string          `json:"username" gorm:"uniqueIndex;not null;size:100" example:"john_doe"`
+	Email              string          `json:"email" gorm:"uniqueIndex;not null;size:255" example:"john@example.com"`
+	Password           string          `json:"-" gorm:"not null;size:255"` // "-" means this field won't be included in JSON
+	FullName           string          `json:"full_name" gorm:"not null;size:255" example:"John Doe"`
+	TenantID           uint            `json:"tenant_id" gorm:"not null;index" example:"1"` // organization the user belongs to
+	Roles              []Role          `json:"roles" gorm:"many2many:user_roles"`
+	IsActive           bool            `json:"is_active" gorm:"default:false"`            // a default of true would make GORM skip an explicit false on create
+	MustChangePassword bool            `json:"must_change_password" gorm:"default:false"` // every route except the password change is blocked until cleared
+	IsServiceAccount   bool            `json:"is_service_account" gorm:"default:false"`   // service accounts authenticate with API keys only
+	TwoFactorEnabled   bool            `json:"two_factor_enabled" gorm:"default:false"`
+	TwoFactorSecret    string          `json:"-" gorm:"size:64"`                             // base32 TOTP secret, set when enrollment starts
+	TwoFactorLastStep  int64           `json:"-" gorm:"default:0"`                           // last accepted TOTP time step, codes cannot be replayed
+	RegistrationStatus string          `json:"registration_status,omitempty" gorm:"size:32"` // set while a self-registered user awaits verification or approval
+	EmailVerifiedAt    *time.Time      `json:"email_verified_at,omitempty"`
+	PendingEmail       string          `json:"pending_email,omitempty" gorm:"size:255"` // new address waiting for verification, replaces Email once verified
+	Preferences        UserPreferences `json:"preferences" gorm:"serializer:json;size:1000"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt  `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 // UserResponse represents a user response for Swagger documentation (excludes sensitive fields)
 type UserResponse struct {
-	ID        uint      `json:"id" example:"1"`
-	Username  string    `json:"username" example:"john_doe"`
-	Email     string    `json:"email" example:"john@example.com"`
-	FullName  string    `json:"full_name" example:"John Doe"`
-	Role      string    `json:"role" example:"user"`
-	IsActive  bool      `json:"is_active" example:"true"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 uint            `json:"id" example:"1"`
+	Username           string          `json:"username" example:"john_doe"`
+	Email              string          `json:"email" example:"john@example.com"`
+	FullName           string          `json:"full_name" example:"John Doe"`
+	TenantID           uint            `json:"tenant_id" example:"1"`
+	Roles              []string        `json:"roles" example:"user"`
+	IsActive           bool            `json:"is_active" example:"true"`
+	MustChangePassword bool            `json:"must_change_password" example:"false"`
+	IsServiceAccount   bool            `json:"is_service_account" example:"false"`
+	TwoFactorEnabled   bool            `json:"two_factor_enabled" example:"false"`
+	RegistrationStatus string          `json:"registration_status,omitempty" example:"pending_approval"`
+	EmailVerified      bool            `json:"email_verified" example:"true"`
+	PendingEmail       string          `json:"pending_email,omitempty" example:"john.doe@example.com"`
+	Preferences        UserPreferences `json:"preferences"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
 }
 
 // ToUserResponse converts a User to UserResponse
 func (u *User) ToUserResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		FullName:  u.FullName,
-		Role:      u.Role,
-		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                 u.ID,
+		Username:           u.Username,
+		Email:              u.Email,
+		FullName:           u.FullName,
+		TenantID:           u.TenantID,
+		Roles:              u.RoleNames(),
+		IsActive:           u.IsActive,
+		MustChangePassword: u.MustChangePassword,
+		IsServiceAccount:   u.IsServiceAccount,
+		TwoFactorEnabled:   u.TwoFactorEnabled,
+		RegistrationStatus: u.RegistrationStatus,
+		EmailVerified:      u.EmailVerifiedAt != nil,
+		PendingEmail:       u.PendingEmail,
+		Preferences:        u.Preferences,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
 	}
 }
 
+// RoleNames returns the names of the roles assigned to the user
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// RequiresTwoFactor reports whether any role of the user requires two-factor authentication
+func (u *User) RequiresTwoFactor() bool {
+	for _, role := range u.Roles {
+		if role.RequireTwoFactor {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectivePermissions returns the union of the permissions granted by all roles of the user
+func (u *User) EffectivePermissions() []string {
+	seen := make(map[string]bool)
+	permissions := []string{}
+	for _, role := range u.Roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				permissions = append(permissions, permission.Name)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// CreateUserRequest represents an admin request to create a user
+type CreateUserRequest struct {
+	Username         string   `json:"username" binding:"required,max=100" example:"john_doe"`
+	Email            string   `json:"email" binding:"required,email,max=255" example:"john@example.com"`
+	Password         string   `json:"password" binding:"omitempty,min=8" example:"ChangeMe123"` // required unless is_service_account is set
+	FullName         string   `json:"full_name" binding:"required,max=255" example:"John Doe"`
+	Roles            []string `json:"roles" example:"user"` // defaults to ["user"]
+	IsServiceAccount bool     `json:"is_service_account" example:"false"`
+	TenantID         *uint    `json:"tenant_id,omitempty" example:"1"` // defaults to the organization of the admin; other organizations need organizations:manage
+}
+
+// UpdateUserRequest represents an admin request to update a user (with optional fields)
+type UpdateUserRequest struct {
+	Email    *string   `json:"email,omitempty" binding:"omitempty,email,max=255" example:"john@example.com"`
+	FullName *string   `json:"full_name,omitempty" binding:"omitempty,max=255" example:"John Doe"`
+	Roles    *[]string `json:"roles,omitempty" example:"viewer"`
+}
+
 // LoginRequest represents the login request structure
 type LoginRequest struct {
 	Username string `json:"username" binding:"required" example:"admin"`
 	Password string `json:"password" binding:"required" example:"admin123"`
 }
 
-// LoginResponse represents the login response structure
+// ChangePasswordRequest represents the password change request structure
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"admin123"`
+	NewPassword     string `json:"new_password" binding:"required,min=8" example:"N3wStr0ngPassw0rd"`
+}
+
+// LoginResponse represents the login response structure.
+// When two-factor authentication is enabled only ChallengeToken is set; exchange it at /auth/2fa/verify.
 type LoginResponse struct {
-	Token        string       `json:"token"`
-	RefreshToken string       `json:"refresh_token"`
-	User         UserResponse `json:"user"`
+	Token             string        `json:"token,omitempty"`
+	RefreshToken      string        `json:"refresh_token,omitempty"`
+	User              *UserResponse `json:"user,omitempty"`
+	TwoFactorRequired bool          `json:"two_factor_required,omitempty" example:"false"`
+	ChallengeToken    string        `json:"challenge_token,omitempty"`
 }
 
-// Claims represents the JWT claims structure
+// Claims represents the JWT claims structure.
+// RegisteredClaims.ID carries the jti used to revoke a single token.
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID             uint     `json:"user_id"`
+	Username           string   `json:"username"`
+	Roles              []string `json:"roles"`
+	Permissions        []string `json:"permissions"`   // effective permissions of all roles
+	TenantID           uint     `json:"tenant_id"`     // organization all data access is scoped to
+	SessionID          string   `json:"sid,omitempty"` // refresh token family the token was issued with
+	MustChangePassword bool     `json:"must_change_password,omitempty"`
+	TwoFactorSetup     bool     `json:"two_factor_setup,omitempty"` // every route except the 2FA enrollment is blocked until the user enrolls
+	Purpose            string   `json:"purpose,omitempty"`          // set on tokens that are not access tokens, such as 2FA login challenges
+	ClientID           string   `json:"client_id,omitempty"`        // OAuth client the token was issued to; such tokens have no user
+	Scope              string   `json:"scope,omitempty"`            // space separated scopes granted to an OAuth client or a scoped API key
+	Act                *Actor   `json:"act,omitempty"`              // admin impersonating the user; the token acts as the user on their behalf
 	jwt.RegisteredClaims
 }
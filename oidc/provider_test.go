@@ -0,0 +1,220 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testIdP is a stand-in identity provider: it serves discovery, an authorization endpoint that
+// signs in "somchai" right away, a token endpoint checking client credentials and PKCE, and its keys
+type testIdP struct {
+	server     *httptest.Server
+	key        ed25519.PrivateKey
+	signingKey ed25519.PrivateKey // signs the ID tokens, normally key
+	nonce      string             // nonce put into ID tokens, normally the one sent to the authorization endpoint
+
+	mu    sync.Mutex
+	codes map[string]testAuthRequest
+}
+
+type testAuthRequest struct {
+	challenge string
+	nonce     string
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &testIdP{key: key, signingKey: key, codes: make(map[string]testAuthRequest)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Discovery{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JWKSURI:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		public := idp.key.Public().(ed25519.PublicKey)
+		json.NewEncoder(w).Encode(model.JWKS{Keys: []model.JWK{{
+			Kty: "OKP", Kid: "idp-1", Use: "sig", Alg: "EdDSA", Crv: "Ed25519",
+			X: base64.RawURLEncoding.EncodeToString(public),
+		}}})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *testIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != "topics-api" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+	idp.mu.Lock()
+	idp.codes[code] = testAuthRequest{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	idp.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	redirect.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (idp *testIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, _ := r.BasicAuth()
+	if clientID != "topics-api" || secret != "s3cret" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	idp.mu.Lock()
+	request, ok := idp.codes[r.PostFormValue("code")]
+	delete(idp.codes, r.PostFormValue("code"))
+	idp.mu.Unlock()
+	if !ok || CodeChallenge(r.PostFormValue("code_verifier")) != request.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	nonce := request.nonce
+	if idp.nonce != "" {
+		nonce = idp.nonce
+	}
+	claims := IDTokenClaims{
+		Email:             "somchai@example.com",
+		EmailVerified:     true,
+		PreferredUsername: "somchai",
+		Nonce:             nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    idp.server.URL,
+			Subject:   "user-123",
+			Audience:  jwt.ClaimStrings{"topics-api"},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = "idp-1"
+	idToken, err := token.SignedString(idp.signingKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: idToken, ExpiresIn: 300})
+}
+
+func TestProviderLogin(t *testing.T) {
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		setup        func(idp *testIdP)
+		codeVerifier string // sent to the token endpoint, normally the one the challenge was derived from
+		wantErr      string
+	}{
+		{name: "successful login"},
+		{
+			name:         "wrong PKCE verifier",
+			codeVerifier: "not-the-verifier",
+			wantErr:      "token exchange failed",
+		},
+		{
+			name:    "ID token with a bad signature",
+			setup:   func(idp *testIdP) { idp.signingKey = otherKey },
+			wantErr: "invalid id token: token signature is invalid",
+		},
+		{
+			name:    "ID token with another nonce",
+			setup:   func(idp *testIdP) { idp.nonce = "replayed-nonce" },
+			wantErr: "invalid id token: nonce mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIdP(t)
+			if tt.setup != nil {
+				tt.setup(idp)
+			}
+			provider := NewProvider(&config.OIDCConfig{
+				IssuerURL:    idp.server.URL,
+				ClientID:     "topics-api",
+				ClientSecret: "s3cret",
+				RedirectURL:  "http://localhost:8080/auth/oidc/callback",
+				Scopes:       []string{"profile", "email"},
+			})
+			ctx := context.Background()
+
+			state, nonce, codeVerifier := "state-1", "nonce-1", rand.Text()+rand.Text()
+			authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Follow the browser to the provider and take the code from the redirect to our callback
+			browser := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+			resp, err := browser.Get(authURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			callback, err := url.Parse(resp.Header.Get("Location"))
+			if err != nil || callback.Query().Get("state") != state {
+				t.Fatalf("unexpected redirect %q: %v", resp.Header.Get("Location"), err)
+			}
+
+			if tt.codeVerifier != "" {
+				codeVerifier = tt.codeVerifier
+			}
+			claims, err := func() (*IDTokenClaims, error) {
+				tokens, err := provider.Exchange(ctx, callback.Query().Get("code"), codeVerifier)
+				if err != nil {
+					return nil, err
+				}
+				return provider.VerifyIDToken(ctx, tokens.IDToken, nonce)
+			}()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("login error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Subject != "user-123" || claims.PreferredUsername != "somchai" || !claims.EmailVerified {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
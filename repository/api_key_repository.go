@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(key *model.APIKey) error
+	FindAll(ctx context.Context) ([]model.APIKey, error)
+	FindAllByUserID(userID uint) ([]model.APIKey, error)
+	FindByID(id uint) (*model.APIKey, error)
+	FindByPrefix(prefix string) (*model.APIKey, error)
+	Revoke(id uint, revokedAt time.Time) error
+	UpdateLastUsed(id uint, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (r *apiKeyRepository) Create(key *model.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// FindAll returns the keys of all users in the caller's organization
+func (r *apiKeyRepository) FindAll(ctx context.Context) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	tenantUsers := r.db.Model(&model.User{}).Select("id").Scopes(TenantScope(ctx))
+	err := r.db.WithContext(ctx).Where("user_id IN (?)", tenantUsers).Order("id ASC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) FindAllByUserID(userID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) FindByID(id uint) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.First(&key, "id = ?", id).Error
+	return &key, err
+}
+
+func (r *apiKeyRepository) FindByPrefix(prefix string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.First(&key, "prefix = ?", prefix).Error
+	return &key, err
+}
+
+func (r *apiKeyRepository) Revoke(id uint, revokedAt time.Time) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *apiKeyRepository) UpdateLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&model.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository interface {
+	Create(token *model.EmailVerificationToken) error
+	FindByTokenHash(tokenHash string) (*model.EmailVerificationToken, error)
+	MarkUsed(id uint, usedAt time.Time) (bool, error)
+	InvalidateByUserID(userID uint, usedAt time.Time) error
+}
+
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) EmailVerificationRepository {
+	return &emailVerificationRepository{db}
+}
+
+func (r *emailVerificationRepository) Create(token *model.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *emailVerificationRepository) FindByTokenHash(tokenHash string) (*model.EmailVerificationToken, error) {
+	var token model.EmailVerificationToken
+	err := r.db.First(&token, "token_hash = ?", tokenHash).Error
+	return &token, err
+}
+
+// MarkUsed consumes a token. It reports false when the token had already been used.
+func (r *emailVerificationRepository) MarkUsed(id uint, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// InvalidateByUserID consumes every outstanding token of a user
+func (r *emailVerificationRepository) InvalidateByUserID(userID uint, usedAt time.Time) error {
+	return r.db.Model(&model.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", usedAt).Error
+}
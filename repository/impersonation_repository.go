@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type ImpersonationRepository interface {
+	Create(impersonation *model.Impersonation) error
+	FindAll(ctx context.Context, activeOnly bool, now time.Time) ([]model.Impersonation, error)
+	FindByID(ctx context.Context, id uint) (*model.Impersonation, error)
+	End(id uint, endedAt time.Time, endedBy string) (bool, error)
+}
+
+type impersonationRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationRepository(db *gorm.DB) ImpersonationRepository {
+	return &impersonationRepository{db}
+}
+
+func (r *impersonationRepository) Create(impersonation *model.Impersonation) error {
+	return r.db.Create(impersonation).Error
+}
+
+// FindAll returns the impersonations of the caller's organization, newest first
+func (r *impersonationRepository) FindAll(ctx context.Context, activeOnly bool, now time.Time) ([]model.Impersonation, error) {
+	var impersonations []model.Impersonation
+	query := r.db.WithContext(ctx).Scopes(TenantScope(ctx))
+	if activeOnly {
+		query = query.Where("ended_at IS NULL AND expires_at > ?", now)
+	}
+	err := query.Order("id DESC").Find(&impersonations).Error
+	return impersonations, err
+}
+
+func (r *impersonationRepository) FindByID(ctx context.Context, id uint) (*model.Impersonation, error) {
+	var impersonation model.Impersonation
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&impersonation, "id = ?", id).Error
+	return &impersonation, err
+}
+
+// End marks an impersonation as ended. It reports false when it had already been ended.
+func (r *impersonationRepository) End(id uint, endedAt time.Time, endedBy string) (bool, error) {
+	result := r.db.Model(&model.Impersonation{}).
+		Where("id = ? AND ended_at IS NULL", id).
+		Updates(map[string]interface{}{"ended_at": endedAt, "ended_by": endedBy})
+	return result.RowsAffected > 0, result.Error
+}
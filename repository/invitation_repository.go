@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	Create(invitation *model.Invitation) error
+	FindAll(ctx context.Context) ([]model.Invitation, error)
+	FindByID(ctx context.Context, id uint) (*model.Invitation, error)
+	FindByTokenHash(tokenHash string) (*model.Invitation, error)
+	ExistsPending(email string, now time.Time) bool
+	Renew(id uint, tokenHash string, expiresAt time.Time) (bool, error)
+	Revoke(id uint, revokedAt time.Time) (bool, error)
+	MarkAccepted(id uint, acceptedAt time.Time) (bool, error)
+	Reopen(id uint) error
+	SetUserID(id uint, userID uint) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db}
+}
+
+func (r *invitationRepository) Create(invitation *model.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// FindAll returns the invitations of the caller's organization, newest first
+func (r *invitationRepository) FindAll(ctx context.Context) ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Preload("Role").Order("id DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepository) FindByID(ctx context.Context, id uint) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Preload("Role").First(&invitation, "id = ?", id).Error
+	return &invitation, err
+}
+
+func (r *invitationRepository) FindByTokenHash(tokenHash string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.Preload("Role.Permissions").First(&invitation, "token_hash = ?", tokenHash).Error
+	return &invitation, err
+}
+
+// ExistsPending reports whether an invitation to the address can still be accepted
+func (r *invitationRepository) ExistsPending(email string, now time.Time) bool {
+	var count int64
+	r.db.Model(&model.Invitation{}).
+		Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", email, now).
+		Count(&count)
+	return count > 0
+}
+
+// Renew replaces the token of an open invitation, so only the most recently mailed link works.
+// It reports false when the invitation has been accepted or revoked in the meantime.
+func (r *invitationRepository) Renew(id uint, tokenHash string, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&model.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"token_hash": tokenHash, "expires_at": expiresAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Revoke withdraws an open invitation. It reports false when it had been accepted or revoked already.
+func (r *invitationRepository) Revoke(id uint, revokedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkAccepted consumes an invitation. It reports false when it had been accepted or revoked already.
+func (r *invitationRepository) MarkAccepted(id uint, acceptedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("accepted_at", acceptedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Reopen makes a consumed invitation usable again, for when creating the account failed
+func (r *invitationRepository) Reopen(id uint) error {
+	return r.db.Model(&model.Invitation{}).Where("id = ?", id).Update("accepted_at", nil).Error
+}
+
+// SetUserID links an accepted invitation to the account created with it
+func (r *invitationRepository) SetUserID(id uint, userID uint) error {
+	return r.db.Model(&model.Invitation{}).Where("id = ?", id).Update("user_id", userID).Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type LoginThrottleRepository interface {
+	FindByKey(key string) (*model.LoginThrottle, error)
+	RecordFailure(key string, now, windowStart time.Time) (int, error)
+	ExtendLockout(key string, lockedUntil time.Time) error
+	DeleteByKey(key string) error
+}
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &loginThrottleRepository{db}
+}
+
+func (r *loginThrottleRepository) FindByKey(key string) (*model.LoginThrottle, error) {
+	var throttle model.LoginThrottle
+	err := r.db.First(&throttle, "[key] = ?", key).Error
+	return &throttle, err
+}
+
+// RecordFailure counts a failed attempt for the key in a single statement and returns the new count,
+// so concurrent failures cannot overwrite each other. Failures before windowStart are forgotten.
+func (r *loginThrottleRepository) RecordFailure(key string, now, windowStart time.Time) (int, error) {
+	var failedAttempts int
+	err := r.db.Raw(`MERGE login_throttles WITH (HOLDLOCK) AS t
+USING (SELECT ? AS [key]) AS s ON t.[key] = s.[key]
+WHEN MATCHED THEN UPDATE SET
+	failed_attempts = CASE WHEN t.last_failed_at < ? THEN 1 ELSE t.failed_attempts + 1 END,
+	last_failed_at = ?, updated_at = ?
+WHEN NOT MATCHED THEN INSERT ([key], failed_attempts, last_failed_at, updated_at) VALUES (s.[key], 1, ?, ?)
+OUTPUT inserted.failed_attempts;`, key, windowStart, now, now, now, now).Scan(&failedAttempts).Error
+	return failedAttempts, err
+}
+
+// ExtendLockout locks the key until lockedUntil unless it is already locked for longer
+func (r *loginThrottleRepository) ExtendLockout(key string, lockedUntil time.Time) error {
+	return r.db.Model(&model.LoginThrottle{}).
+		Where("[key] = ? AND (locked_until IS NULL OR locked_until < ?)", key, lockedUntil).
+		UpdateColumn("locked_until", lockedUntil).Error
+}
+
+func (r *loginThrottleRepository) DeleteByKey(key string) error {
+	return r.db.Delete(&model.LoginThrottle{}, "[key] = ?", key).Error
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type OAuthClientRepository interface {
+	Create(client *model.OAuthClient) error
+	FindAll(ctx context.Context) ([]model.OAuthClient, error)
+	FindByID(ctx context.Context, id uint) (*model.OAuthClient, error)
+	FindByClientID(clientID string) (*model.OAuthClient, error)
+	Revoke(id uint, revokedAt time.Time) error
+	UpdateLastUsed(id uint, usedAt time.Time) error
+}
+
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db}
+}
+
+func (r *oauthClientRepository) Create(client *model.OAuthClient) error {
+	return r.db.Create(client).Error
+}
+
+func (r *oauthClientRepository) FindAll(ctx context.Context) ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Order("id ASC").Find(&clients).Error
+	return clients, err
+}
+
+func (r *oauthClientRepository) FindByID(ctx context.Context, id uint) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&client, "id = ?", id).Error
+	return &client, err
+}
+
+// FindByClientID looks up a client across organizations while authenticating it
+func (r *oauthClientRepository) FindByClientID(clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.db.First(&client, "client_id = ?", clientID).Error
+	return &client, err
+}
+
+func (r *oauthClientRepository) Revoke(id uint, revokedAt time.Time) error {
+	return r.db.Model(&model.OAuthClient{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *oauthClientRepository) UpdateLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&model.OAuthClient{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
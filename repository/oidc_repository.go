@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	Create(identity *model.UserIdentity) error
+	FindByIssuerAndSubject(issuer, subject string) (*model.UserIdentity, error)
+	UpdateLastLogin(id uint, email string, at time.Time) error
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db}
+}
+
+func (r *userIdentityRepository) Create(identity *model.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *userIdentityRepository) FindByIssuerAndSubject(issuer, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.First(&identity, "issuer = ? AND subject = ?", issuer, subject).Error
+	return &identity, err
+}
+
+func (r *userIdentityRepository) UpdateLastLogin(id uint, email string, at time.Time) error {
+	return r.db.Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"email": email, "last_login_at": at}).Error
+}
+
+type OIDCAuthRequestRepository interface {
+	Create(request *model.OIDCAuthRequest) error
+	FindByStateHash(stateHash string) (*model.OIDCAuthRequest, error)
+	Consume(id uint) (bool, error)
+	DeleteExpired(before time.Time) error
+}
+
+type oidcAuthRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCAuthRequestRepository(db *gorm.DB) OIDCAuthRequestRepository {
+	return &oidcAuthRequestRepository{db}
+}
+
+func (r *oidcAuthRequestRepository) Create(request *model.OIDCAuthRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *oidcAuthRequestRepository) FindByStateHash(stateHash string) (*model.OIDCAuthRequest, error) {
+	var request model.OIDCAuthRequest
+	err := r.db.First(&request, "state_hash = ?", stateHash).Error
+	return &request, err
+}
+
+// Consume deletes a pending login. It reports false when the login had already been consumed.
+func (r *oidcAuthRequestRepository) Consume(id uint) (bool, error) {
+	result := r.db.Delete(&model.OIDCAuthRequest{}, "id = ?", id)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *oidcAuthRequestRepository) DeleteExpired(before time.Time) error {
+	return r.db.Delete(&model.OIDCAuthRequest{}, "expires_at < ?", before).Error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository interface {
+	Create(organization *model.Organization) error
+	FindAll() ([]model.Organization, error)
+	FindByID(id uint) (*model.Organization, error)
+	FindBySlug(slug string) (*model.Organization, error)
+	FindByName(name string) (*model.Organization, error)
+	Update(organization *model.Organization) error
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db}
+}
+
+func (r *organizationRepository) Create(organization *model.Organization) error {
+	return r.db.Create(organization).Error
+}
+
+func (r *organizationRepository) FindAll() ([]model.Organization, error) {
+	var organizations []model.Organization
+	err := r.db.Order("name ASC").Find(&organizations).Error
+	return organizations, err
+}
+
+func (r *organizationRepository) FindByID(id uint) (*model.Organization, error) {
+	var organization model.Organization
+	err := r.db.First(&organization, "id = ?", id).Error
+	return &organization, err
+}
+
+func (r *organizationRepository) FindBySlug(slug string) (*model.Organization, error) {
+	var organization model.Organization
+	err := r.db.First(&organization, "slug = ?", slug).Error
+	return &organization, err
+}
+
+func (r *organizationRepository) FindByName(name string) (*model.Organization, error) {
+	var organization model.Organization
+	err := r.db.First(&organization, "name = ?", name).Error
+	return &organization, err
+}
+
+func (r *organizationRepository) Update(organization *model.Organization) error {
+	return r.db.Save(organization).Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Create(token *model.PasswordResetToken) error
+	FindByTokenHash(tokenHash string) (*model.PasswordResetToken, error)
+	MarkUsed(id uint, usedAt time.Time) (bool, error)
+	InvalidateByUserID(userID uint, usedAt time.Time) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db}
+}
+
+func (r *passwordResetRepository) Create(token *model.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *passwordResetRepository) FindByTokenHash(tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	err := r.db.First(&token, "token_hash = ?", tokenHash).Error
+	return &token, err
+}
+
+// MarkUsed consumes a token. It reports false when the token had already been used.
+func (r *passwordResetRepository) MarkUsed(id uint, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// InvalidateByUserID consumes every outstanding token of a user
+func (r *passwordResetRepository) InvalidateByUserID(userID uint, usedAt time.Time) error {
+	return r.db.Model(&model.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", usedAt).Error
+}
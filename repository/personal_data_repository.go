@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"unicode/utf8"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// PersonalDataRepository collects and erases the personal data of a user across tables
+type PersonalDataRepository interface {
+	FindTopicsByActor(tenantID uint, username string) ([]model.Topic, error)
+	FindTopicDetailsByActor(tenantID uint, username string) ([]model.TopicDetail, error)
+	FindSessions(userID uint) ([]model.Session, error)
+	FindIdentities(userID uint) ([]model.UserIdentity, error)
+	FindAPIKeys(userID uint) ([]model.APIKey, error)
+	FindSecurityEvents(userID uint, username string) ([]model.SecurityEvent, error)
+	FindImpersonations(userID uint) ([]model.Impersonation, error)
+	Anonymize(user *model.User, previousUsername string) error
+}
+
+type personalDataRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalDataRepository(db *gorm.DB) PersonalDataRepository {
+	return &personalDataRepository{db}
+}
+
+// FindTopicsByActor returns the topics of an organization the user created or last updated
+func (r *personalDataRepository) FindTopicsByActor(tenantID uint, username string) ([]model.Topic, error) {
+	var topics []model.Topic
+	err := r.db.Where("tenant_id = ? AND (created_by = ? OR updated_by = ?)", tenantID, username, username).
+		Order("id ASC").Find(&topics).Error
+	return topics, err
+}
+
+// FindTopicDetailsByActor returns the topic details of an organization the user created or last updated
+func (r *personalDataRepository) FindTopicDetailsByActor(tenantID uint, username string) ([]model.TopicDetail, error) {
+	var details []model.TopicDetail
+	err := r.db.Where("tenant_id = ? AND (created_by = ? OR updated_by = ?)", tenantID, username, username).
+		Order("id ASC").Find(&details).Error
+	return details, err
+}
+
+// FindSessions returns every session of the user
+func (r *personalDataRepository) FindSessions(userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *personalDataRepository) FindIdentities(userID uint) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&identities).Error
+	return identities, err
+}
+
+func (r *personalDataRepository) FindAPIKeys(userID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&keys).Error
+	return keys, err
+}
+
+// FindSecurityEvents returns the security events of the user, including failed logins with their username
+// that were recorded without a user, such as attempts while the account was locked
+func (r *personalDataRepository) FindSecurityEvents(userID uint, username string) ([]model.SecurityEvent, error) {
+	var events []model.SecurityEvent
+	err := r.db.Where("user_id = ? OR (user_id IS NULL AND username = ?)", userID, username).
+		Order("created_at ASC").Find(&events).Error
+	return events, err
+}
+
+// FindImpersonations returns the impersonations of the user and those the user started as an admin
+func (r *personalDataRepository) FindImpersonations(userID uint) ([]model.Impersonation, error) {
+	var impersonations []model.Impersonation
+	err := r.db.Where("user_id = ? OR actor_id = ?", userID, userID).Order("id ASC").Find(&impersonations).Error
+	return impersonations, err
+}
+
+// Anonymize saves the already anonymized user, soft-deletes it and removes everything else that identifies
+// the person. Topics, details and clients they authored are kept and credited to the anonymized username.
+func (r *personalDataRepository) Anonymize(user *model.User, previousUsername string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles").Save(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id = ?", user.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM team_members WHERE user_id = ?", user.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subject_type = ? AND subject_id = ?", model.TopicACLSubjectUser, user.ID).Delete(&model.TopicACLEntry{}).Error; err != nil {
+			return err
+		}
+
+		// Usernames are unique across organizations, and admins acting through an impersonation
+		// leave their name in the organization of the impersonated user
+		actorColumns := []struct {
+			table  interface{}
+			column string
+		}{
+			{&model.Topic{}, "created_by"}, {&model.Topic{}, "updated_by"},
+			{&model.TopicDetail{}, "created_by"}, {&model.TopicDetail{}, "updated_by"},
+			{&model.TopicACLEntry{}, "created_by"}, {&model.TopicACLEntry{}, "updated_by"},
+			{&model.OAuthClient{}, "created_by"}, {&model.Invitation{}, "invited_by"},
+			{&model.Impersonation{}, "ended_by"}, {&model.SecurityEvent{}, "actor"},
+		}
+		for _, actorColumn := range actorColumns {
+			if err := renameActor(tx.Model(actorColumn.table), actorColumn.column, previousUsername, user.Username); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.Impersonation{}).Where("user_id = ?", user.ID).Update("username", user.Username).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Impersonation{}).Where("actor_id = ?", user.ID).Update("actor_username", user.Username).Error; err != nil {
+			return err
+		}
+		// Failed logins recorded without a user only carry the username
+		if err := tx.Model(&model.SecurityEvent{}).Where("username = ?", previousUsername).Update("username", user.Username).Error; err != nil {
+			return err
+		}
+
+		for _, table := range []interface{}{
+			&model.Session{}, &model.RefreshToken{}, &model.UserIdentity{}, &model.RecoveryCode{},
+			&model.EmailVerificationToken{}, &model.PasswordResetToken{}, &model.APIKey{}, &model.SecurityEvent{},
+		} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(table).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(user).Error
+	})
+}
+
+// renameActor replaces a username in an actor column, where it is either stored on its own or as part of
+// "user (via admin)" for changes made through an impersonation
+func renameActor(table *gorm.DB, column, previousUsername, username string) error {
+	if err := table.Session(&gorm.Session{}).Where(column+" = ?", previousUsername).Update(column, username).Error; err != nil {
+		return err
+	}
+
+	// The user acted while being impersonated
+	prefix := previousUsername + " (via "
+	prefixLen := utf8.RuneCountInString(prefix)
+	if err := table.Session(&gorm.Session{}).Where("LEFT("+column+", ?) = ?", prefixLen, prefix).
+		Update(column, gorm.Expr("? + SUBSTRING("+column+", ?, LEN("+column+"))", username+" (via ", prefixLen+1)).Error; err != nil {
+		return err
+	}
+
+	// The user impersonated someone else
+	suffix := " (via " + previousUsername + ")"
+	suffixLen := utf8.RuneCountInString(suffix)
+	return table.Session(&gorm.Session{}).Where("RIGHT("+column+", ?) = ?", suffixLen, suffix).
+		Update(column, gorm.Expr("LEFT("+column+", LEN("+column+") - ?) + ?", suffixLen, " (via "+username+")")).Error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	ReplaceForUser(userID uint, codes []model.RecoveryCode) error
+	FindUnused(userID uint, codeHash string) (*model.RecoveryCode, error)
+	MarkUsed(id uint, usedAt time.Time) (bool, error)
+	DeleteByUserID(userID uint) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db}
+}
+
+// ReplaceForUser removes all recovery codes of a user and stores the new ones
+func (r *recoveryCodeRepository) ReplaceForUser(userID uint, codes []model.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *recoveryCodeRepository) FindUnused(userID uint, codeHash string) (*model.RecoveryCode, error) {
+	var code model.RecoveryCode
+	err := r.db.First(&code, "user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).Error
+	return &code, err
+}
+
+// MarkUsed consumes a recovery code. It reports false when the code had already been used.
+func (r *recoveryCodeRepository) MarkUsed(id uint, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *recoveryCodeRepository) DeleteByUserID(userID uint) error {
+	return r.db.Delete(&model.RecoveryCode{}, "user_id = ?", userID).Error
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	Create(role *model.Role) error
+	FindAll() ([]model.Role, error)
+	FindByID(id uint) (*model.Role, error)
+	FindByNames(names []string) ([]model.Role, error)
+	Update(role *model.Role) error
+	ReplacePermissions(role *model.Role, permissions []model.Permission) error
+	Delete(id uint) error
+	FindAllPermissions() ([]model.Permission, error)
+	FindPermissionsByNames(names []string) ([]model.Permission, error)
+	FindUserIDs(roleID uint) ([]uint, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db}
+}
+
+func (r *roleRepository) Create(role *model.Role) error {
+	return r.db.Omit("Permissions.*").Create(role).Error
+}
+
+func (r *roleRepository) FindAll() ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Preload("Permissions").Order("name ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) FindByID(id uint) (*model.Role, error) {
+	var role model.Role
+	err := r.db.Preload("Permissions").First(&role, "id = ?", id).Error
+	return &role, err
+}
+
+func (r *roleRepository) FindByNames(names []string) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Preload("Permissions").Where("name IN ?", names).Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) Update(role *model.Role) error {
+	return r.db.Omit("Permissions").Save(role).Error
+}
+
+func (r *roleRepository) ReplacePermissions(role *model.Role, permissions []model.Permission) error {
+	return r.db.Model(role).Omit("Permissions.*").Association("Permissions").Replace(permissions)
+}
+
+// Delete removes a role together with its permission grants and user assignments
+func (r *roleRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		role := &model.Role{ID: id}
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+}
+
+func (r *roleRepository) FindAllPermissions() ([]model.Permission, error) {
+	var permissions []model.Permission
+	err := r.db.Order("name ASC").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *roleRepository) FindPermissionsByNames(names []string) ([]model.Permission, error) {
+	var permissions []model.Permission
+	err := r.db.Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+// FindUserIDs returns the IDs of the users the role is assigned to
+func (r *roleRepository) FindUserIDs(roleID uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Table("user_roles").Where("role_id = ?", roleID).Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// TenantScope restricts a query on a tenant-owned table to the organization of the principal in ctx.
+// Requests without a principal are scoped to tenant 0 and see nothing.
+func TenantScope(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	tenantID := model.TenantFromContext(ctx)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("tenant_id = ?", tenantID)
+	}
+}
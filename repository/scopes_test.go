@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+func TestTenantScopedReads(t *testing.T) {
+	reads := []struct {
+		name string
+		read func(ctx context.Context, db *gorm.DB) error
+	}{
+		{"topics", func(ctx context.Context, db *gorm.DB) error {
+			_, err := NewTopicRepository(db).FindAll(ctx)
+			return err
+		}},
+		{"topic by id", func(ctx context.Context, db *gorm.DB) error {
+			_, err := NewTopicRepository(db).FindByID(ctx, 42)
+			return err
+		}},
+		{"topic by name", func(ctx context.Context, db *gorm.DB) error {
+			_, err := NewTopicRepository(db).FindByName(ctx, "ยา")
+			return err
+		}},
+		{"topic details", func(ctx context.Context, db *gorm.DB) error {
+			_, err := NewTopicDetailRepository(db).FindAllByTopicID(ctx, 42)
+			return err
+		}},
+		{"topic detail by id", func(ctx context.Context, db *gorm.DB) error {
+			_, err := NewTopicDetailRepository(db).FindByID(ctx, 42)
+			return err
+		}},
+		{"topic acl", func(ctx context.Context, db *gorm.DB) error {
+			_, err := NewTopicACLRepository(db).FindByTopicID(ctx, 42)
+			return err
+		}},
+		{"users", func(ctx context.Context, db *gorm.DB) error {
+			_, err := NewUserRepository(db).FindAll(ctx)
+			return err
+		}},
+		{"user in tenant", func(ctx context.Context, db *gorm.DB) error {
+			NewUserRepository(db).ExistsInTenant(ctx, 42)
+			return nil
+		}},
+	}
+
+	principals := []struct {
+		name       string
+		ctx        context.Context
+		wantTenant uint
+	}{
+		{"caller of organization 7", model.WithPrincipal(context.Background(), model.Principal{UserID: 2, Username: "john_doe", TenantID: 7}), 7},
+		{"no principal sees nothing", context.Background(), 0},
+	}
+
+	for _, read := range reads {
+		for _, principal := range principals {
+			t.Run(read.name+"/"+principal.name, func(t *testing.T) {
+				db, err := gorm.Open(sqlserver.Open("sqlserver://localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var queries []*gorm.Statement
+				err = db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+					queries = append(queries, tx.Statement)
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := read.read(principal.ctx, db); err != nil {
+					t.Fatal(err)
+				}
+				if len(queries) == 0 {
+					t.Fatal("no query was run")
+				}
+
+				// Only the first query reads the tenant-owned table, preloads follow from its rows
+				statement := queries[0]
+				if !strings.Contains(statement.SQL.String(), "tenant_id = ") {
+					t.Errorf("query is not scoped to an organization: %s", statement.SQL.String())
+				}
+				if !slices.Contains(statement.Vars, interface{}(principal.wantTenant)) {
+					t.Errorf("query vars = %v, want organization %d", statement.Vars, principal.wantTenant)
+				}
+			})
+		}
+	}
+}
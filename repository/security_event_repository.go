@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type SecurityEventRepository interface {
+	Create(event *model.SecurityEvent) error
+	Find(ctx context.Context, query *model.SecurityEventQuery, allTenants bool) ([]model.SecurityEvent, error)
+}
+
+type securityEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityEventRepository(db *gorm.DB) SecurityEventRepository {
+	return &securityEventRepository{db}
+}
+
+func (r *securityEventRepository) Create(event *model.SecurityEvent) error {
+	return r.db.Create(event).Error
+}
+
+// Find returns the matching events, newest first. Unless allTenants is set only events of the
+// caller's organization are returned; events of unknown users belong to no organization.
+func (r *securityEventRepository) Find(ctx context.Context, query *model.SecurityEventQuery, allTenants bool) ([]model.SecurityEvent, error) {
+	db := r.db.WithContext(ctx)
+	if !allTenants {
+		db = db.Scopes(TenantScope(ctx))
+	}
+
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Username != "" {
+		db = db.Where("username = ?", query.Username)
+	}
+	if query.IPAddress != "" {
+		db = db.Where("ip_address = ?", query.IPAddress)
+	}
+	if query.Success != nil {
+		db = db.Where("success = ?", *query.Success)
+	}
+	if query.Since != nil {
+		db = db.Where("created_at >= ?", *query.Since)
+	}
+	if query.Until != nil {
+		db = db.Where("created_at <= ?", *query.Until)
+	}
+
+	var events []model.SecurityEvent
+	err := db.Order("id DESC").Limit(query.Limit).Offset(query.Offset).Find(&events).Error
+	return events, err
+}
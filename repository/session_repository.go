@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *model.Session) error
+	FindByID(id uint) (*model.Session, error)
+	FindActiveByUserID(userID uint, now time.Time) ([]model.Session, error)
+	UpdateActivity(sessionID string, client model.ClientInfo, at time.Time, expiresAt time.Time) error
+	Touch(sessionID string, at time.Time) error
+	Revoke(sessionID string, revokedAt time.Time) error
+	RevokeAllByUserID(userID uint, revokedAt time.Time) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db}
+}
+
+func (r *sessionRepository) Create(session *model.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(id uint) (*model.Session, error) {
+	var session model.Session
+	err := r.db.First(&session, "id = ?", id).Error
+	return &session, err
+}
+
+// FindActiveByUserID returns the sessions of a user that are neither revoked nor expired, most recent first
+func (r *sessionRepository) FindActiveByUserID(userID uint, now time.Time) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_activity_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// UpdateActivity records a token refresh, which may come from a new IP address
+func (r *sessionRepository) UpdateActivity(sessionID string, client model.ClientInfo, at time.Time, expiresAt time.Time) error {
+	return r.db.Model(&model.Session{}).
+		Where("session_id = ?", sessionID).
+		Updates(map[string]interface{}{
+			"ip_address":       client.IPAddress,
+			"user_agent":       client.UserAgent,
+			"last_activity_at": at,
+			"expires_at":       expiresAt,
+		}).Error
+}
+
+// Touch records a request made with an access token of the session
+func (r *sessionRepository) Touch(sessionID string, at time.Time) error {
+	return r.db.Model(&model.Session{}).
+		Where("session_id = ? AND last_activity_at < ?", sessionID, at).
+		Update("last_activity_at", at).Error
+}
+
+func (r *sessionRepository) Revoke(sessionID string, revokedAt time.Time) error {
+	return r.db.Model(&model.Session{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *sessionRepository) RevokeAllByUserID(userID uint, revokedAt time.Time) error {
+	return r.db.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
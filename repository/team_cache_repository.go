@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"go-gin-gorm-backend/model"
+)
+
+// cachedTeamsPruneSize is the number of cached users above which expired entries are dropped
+const cachedTeamsPruneSize = 10000
+
+type cachedUserTeams struct {
+	teams    []model.Team
+	cachedAt time.Time
+}
+
+type cachedTeamRepository struct {
+	TeamRepository
+
+	ttl   time.Duration
+	mu    sync.RWMutex
+	users map[uint]cachedUserTeams
+}
+
+// NewCachedTeamRepository caches the teams of each user, which are looked up on every authenticated request.
+// Changes made by this instance take effect immediately; changes made by other instances are picked up within ttl.
+func NewCachedTeamRepository(store TeamRepository, ttl time.Duration) TeamRepository {
+	return &cachedTeamRepository{
+		TeamRepository: store,
+		ttl:            ttl,
+		users:          make(map[uint]cachedUserTeams),
+	}
+}
+
+func (r *cachedTeamRepository) FindByUserID(userID uint) ([]model.Team, error) {
+	r.mu.RLock()
+	entry, ok := r.users[userID]
+	r.mu.RUnlock()
+	if ok && time.Since(entry.cachedAt) < r.ttl {
+		return entry.teams, nil
+	}
+
+	teams, err := r.TeamRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store(userID, teams)
+	return teams, nil
+}
+
+// Update drops the whole cache, like ReplacePermissions and Delete: the change affects every member of the team
+func (r *cachedTeamRepository) Update(team *model.Team) error {
+	defer r.clear()
+	return r.TeamRepository.Update(team)
+}
+
+func (r *cachedTeamRepository) ReplacePermissions(team *model.Team, permissions []model.Permission) error {
+	defer r.clear()
+	return r.TeamRepository.ReplacePermissions(team, permissions)
+}
+
+func (r *cachedTeamRepository) Delete(id uint) error {
+	defer r.clear()
+	return r.TeamRepository.Delete(id)
+}
+
+func (r *cachedTeamRepository) AddMember(teamID, userID uint) error {
+	defer r.forget(userID)
+	return r.TeamRepository.AddMember(teamID, userID)
+}
+
+func (r *cachedTeamRepository) RemoveMember(teamID, userID uint) error {
+	defer r.forget(userID)
+	return r.TeamRepository.RemoveMember(teamID, userID)
+}
+
+func (r *cachedTeamRepository) store(userID uint, teams []model.Team) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if len(r.users) >= cachedTeamsPruneSize {
+		for id, entry := range r.users {
+			if now.Sub(entry.cachedAt) >= r.ttl {
+				delete(r.users, id)
+			}
+		}
+	}
+
+	r.users[userID] = cachedUserTeams{teams: teams, cachedAt: now}
+}
+
+func (r *cachedTeamRepository) forget(userID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, userID)
+}
+
+func (r *cachedTeamRepository) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users = make(map[uint]cachedUserTeams)
+}
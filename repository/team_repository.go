@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// TeamRepository methods taking a ctx only see the teams of the organization in ctx
+type TeamRepository interface {
+	Create(ctx context.Context, team *model.Team) error
+	FindAll(ctx context.Context) ([]model.Team, error)
+	FindByID(ctx context.Context, id uint) (*model.Team, error)
+	FindByName(ctx context.Context, name string) (*model.Team, error)
+	Update(team *model.Team) error
+	ReplacePermissions(team *model.Team, permissions []model.Permission) error
+	Delete(id uint) error
+	AddMember(teamID, userID uint) error
+	RemoveMember(teamID, userID uint) error
+	FindByUserID(userID uint) ([]model.Team, error)
+}
+
+type teamRepository struct {
+	db *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamRepository{db}
+}
+
+// Create stores the team in the organization of ctx
+func (r *teamRepository) Create(ctx context.Context, team *model.Team) error {
+	team.TenantID = model.TenantFromContext(ctx)
+	return r.db.WithContext(ctx).Omit("Permissions.*", "Members").Create(team).Error
+}
+
+func (r *teamRepository) FindAll(ctx context.Context) ([]model.Team, error) {
+	var teams []model.Team
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Preload("Permissions").Preload("Members").Order("name ASC").Find(&teams).Error
+	return teams, err
+}
+
+func (r *teamRepository) FindByID(ctx context.Context, id uint) (*model.Team, error) {
+	var team model.Team
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Preload("Permissions").Preload("Members").First(&team, "id = ?", id).Error
+	return &team, err
+}
+
+func (r *teamRepository) FindByName(ctx context.Context, name string) (*model.Team, error) {
+	var team model.Team
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&team, "name = ?", name).Error
+	return &team, err
+}
+
+func (r *teamRepository) Update(team *model.Team) error {
+	return r.db.Omit("Permissions", "Members").Save(team).Error
+}
+
+func (r *teamRepository) ReplacePermissions(team *model.Team, permissions []model.Permission) error {
+	return r.db.Model(team).Omit("Permissions.*").Association("Permissions").Replace(permissions)
+}
+
+// Delete removes a team together with its permission grants and memberships.
+// Topics it owned are left without an owning team.
+func (r *teamRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Topic{}).Where("owner_team_id = ?", id).Update("owner_team_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subject_type = ? AND subject_id = ?", model.TopicACLSubjectTeam, id).Delete(&model.TopicACLEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM team_permissions WHERE team_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM team_members WHERE team_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Team{ID: id}).Error
+	})
+}
+
+func (r *teamRepository) AddMember(teamID, userID uint) error {
+	return r.db.Model(&model.Team{ID: teamID}).Omit("Members.*").Association("Members").Append(&model.User{ID: userID})
+}
+
+func (r *teamRepository) RemoveMember(teamID, userID uint) error {
+	return r.db.Exec("DELETE FROM team_members WHERE team_id = ? AND user_id = ?", teamID, userID).Error
+}
+
+// FindByUserID returns the teams a user belongs to with their permissions
+func (r *teamRepository) FindByUserID(userID uint) ([]model.Team, error) {
+	var teams []model.Team
+	err := r.db.Preload("Permissions").
+		Joins("JOIN team_members ON team_members.team_id = teams.id").
+		Where("team_members.user_id = ?", userID).
+		Order("teams.id ASC").
+		Find(&teams).Error
+	return teams, err
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// cachedRevocationPruneSize is the number of cached users above which expired entries are dropped
+const cachedRevocationPruneSize = 10000
+
+type cachedUserRevocation struct {
+	before   *time.Time
+	cachedAt time.Time
+}
+
+type cachedTokenRevocationRepository struct {
+	TokenRevocationRepository
+
+	ttl   time.Duration
+	mu    sync.RWMutex
+	users map[uint]cachedUserRevocation
+}
+
+// NewCachedTokenRevocationRepository caches the per-user revocation timestamps of another store, which are
+// looked up on every authenticated request. Revocations made by this instance take effect immediately;
+// revocations made by other instances are picked up within ttl.
+func NewCachedTokenRevocationRepository(store TokenRevocationRepository, ttl time.Duration) TokenRevocationRepository {
+	return &cachedTokenRevocationRepository{
+		TokenRevocationRepository: store,
+		ttl:                       ttl,
+		users:                     make(map[uint]cachedUserRevocation),
+	}
+}
+
+func (r *cachedTokenRevocationRepository) RevokeUserTokens(userID uint, before time.Time) error {
+	if err := r.TokenRevocationRepository.RevokeUserTokens(userID, before); err != nil {
+		return err
+	}
+
+	r.store(userID, &before)
+	return nil
+}
+
+func (r *cachedTokenRevocationRepository) GetUserTokensRevokedBefore(userID uint) (*time.Time, error) {
+	r.mu.RLock()
+	entry, ok := r.users[userID]
+	r.mu.RUnlock()
+	if ok && time.Since(entry.cachedAt) < r.ttl {
+		return entry.before, nil
+	}
+
+	before, err := r.TokenRevocationRepository.GetUserTokensRevokedBefore(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store(userID, before)
+	return before, nil
+}
+
+func (r *cachedTokenRevocationRepository) store(userID uint, before *time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if len(r.users) >= cachedRevocationPruneSize {
+		for id, entry := range r.users {
+			if now.Sub(entry.cachedAt) >= r.ttl {
+				delete(r.users, id)
+			}
+		}
+	}
+
+	r.users[userID] = cachedUserRevocation{before: before, cachedAt: now}
+}
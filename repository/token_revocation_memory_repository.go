@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryTokenRevocationRepository struct {
+	mu            sync.RWMutex
+	revokedTokens map[string]time.Time
+	revokedUsers  map[uint]time.Time
+}
+
+// NewMemoryTokenRevocationRepository returns an in-memory revocation store.
+// Revocations are lost on restart and are not shared between instances, so it suits single-instance deployments and development.
+func NewMemoryTokenRevocationRepository() TokenRevocationRepository {
+	return &memoryTokenRevocationRepository{
+		revokedTokens: make(map[string]time.Time),
+		revokedUsers:  make(map[uint]time.Time),
+	}
+}
+
+func (r *memoryTokenRevocationRepository) RevokeToken(tokenID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Purge entries for tokens that have expired on their own
+	now := time.Now()
+	for id, exp := range r.revokedTokens {
+		if exp.Before(now) {
+			delete(r.revokedTokens, id)
+		}
+	}
+
+	r.revokedTokens[tokenID] = expiresAt
+	return nil
+}
+
+func (r *memoryTokenRevocationRepository) IsTokenRevoked(tokenID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, revoked := r.revokedTokens[tokenID]
+	return revoked, nil
+}
+
+func (r *memoryTokenRevocationRepository) RevokeUserTokens(userID uint, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokedUsers[userID] = before
+	return nil
+}
+
+func (r *memoryTokenRevocationRepository) GetUserTokensRevokedBefore(userID uint) (*time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	before, ok := r.revokedUsers[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &before, nil
+}
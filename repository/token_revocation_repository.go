@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenRevocationRepository stores revoked access tokens and per-user revocation timestamps
+type TokenRevocationRepository interface {
+	RevokeToken(tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(tokenID string) (bool, error)
+	RevokeUserTokens(userID uint, before time.Time) error
+	GetUserTokensRevokedBefore(userID uint) (*time.Time, error)
+}
+
+type tokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRevocationRepository returns a database-backed revocation store shared by all instances
+func NewTokenRevocationRepository(db *gorm.DB) TokenRevocationRepository {
+	return &tokenRevocationRepository{db}
+}
+
+func (r *tokenRevocationRepository) RevokeToken(tokenID string, expiresAt time.Time) error {
+	// Purge entries for tokens that have expired on their own
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&model.RevokedToken{}).Error; err != nil {
+		return err
+	}
+
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.RevokedToken{
+		TokenID:   tokenID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func (r *tokenRevocationRepository) IsTokenRevoked(tokenID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.RevokedToken{}).Where("token_id = ?", tokenID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *tokenRevocationRepository) RevokeUserTokens(userID uint, before time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "updated_at"}),
+	}).Create(&model.UserTokenRevocation{
+		UserID:        userID,
+		RevokedBefore: before,
+	}).Error
+}
+
+func (r *tokenRevocationRepository) GetUserTokensRevokedBefore(userID uint) (*time.Time, error) {
+	var revocation model.UserTokenRevocation
+	err := r.db.First(&revocation, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revocation.RevokedBefore, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// TopicACLRepository methods only see the entries of the organization in ctx
+type TopicACLRepository interface {
+	FindAll(ctx context.Context) ([]model.TopicACLEntry, error)
+	FindByTopicID(ctx context.Context, topicID uint) ([]model.TopicACLEntry, error)
+	Save(ctx context.Context, entry *model.TopicACLEntry) error
+	Delete(ctx context.Context, topicID, id uint) (bool, error)
+}
+
+type topicACLRepository struct {
+	db *gorm.DB
+}
+
+func NewTopicACLRepository(db *gorm.DB) TopicACLRepository {
+	return &topicACLRepository{db}
+}
+
+func (r *topicACLRepository) FindAll(ctx context.Context) ([]model.TopicACLEntry, error) {
+	var entries []model.TopicACLEntry
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Order("topic_id ASC, id ASC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *topicACLRepository) FindByTopicID(ctx context.Context, topicID uint) ([]model.TopicACLEntry, error) {
+	var entries []model.TopicACLEntry
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Where("topic_id = ?", topicID).Order("id ASC").Find(&entries).Error
+	return entries, err
+}
+
+// Save stores the entry in the organization of ctx. An existing entry for the same topic and subject
+// gets the new access level instead of a second entry.
+func (r *topicACLRepository) Save(ctx context.Context, entry *model.TopicACLEntry) error {
+	entry.TenantID = model.TenantFromContext(ctx)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing model.TopicACLEntry
+		err := tx.Scopes(TenantScope(ctx)).
+			Where("topic_id = ? AND subject_type = ? AND subject_id = ?", entry.TopicID, entry.SubjectType, entry.SubjectID).
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(entry).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		entry.ID = existing.ID
+		entry.CreatedBy = existing.CreatedBy
+		entry.CreatedAt = existing.CreatedAt
+		return tx.Model(&existing).Updates(map[string]interface{}{"access": entry.Access, "updated_by": entry.UpdatedBy}).Error
+	})
+}
+
+// Delete removes an entry of the topic and reports whether it existed
+func (r *topicACLRepository) Delete(ctx context.Context, topicID, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Where("topic_id = ?", topicID).Delete(&model.TopicACLEntry{}, "id = ?", id)
+	return result.RowsAffected > 0, result.Error
+}
@@ -1,18 +1,21 @@
 package repository
 
 import (
+	"context"
+
 	"go-gin-gorm-backend/model"
 
 	"gorm.io/gorm"
 )
 
+// TopicDetailRepository methods only see the details of the organization in ctx
 type TopicDetailRepository interface {
-	Create(detail *model.TopicDetail) error
-	FindAllByTopicID(topicID uint) ([]model.TopicDetail, error)
-	FindByID(id uint) (*model.TopicDetail, error)
-	FindByName(name string) (*model.TopicDetail, error)
-	Update(detail *model.TopicDetail) error
-	Delete(id uint) error
+	Create(ctx context.Context, detail *model.TopicDetail) error
+	FindAllByTopicID(ctx context.Context, topicID uint) ([]model.TopicDetail, error)
+	FindByID(ctx context.Context, id uint) (*model.TopicDetail, error)
+	FindByName(ctx context.Context, name string) (*model.TopicDetail, error)
+	Update(ctx context.Context, detail *model.TopicDetail) error
+	Delete(ctx context.Context, id uint) error
 }
 
 type topicDetailRepository struct {
@@ -23,32 +26,36 @@ func NewTopicDetailRepository(db *gorm.DB) TopicDetailRepository {
 	return &topicDetailRepository{db}
 }
 
-func (r *topicDetailRepository) Create(detail *model.TopicDetail) error {
-	return r.db.Create(detail).Error
+// Create stores the detail in the organization of ctx
+func (r *topicDetailRepository) Create(ctx context.Context, detail *model.TopicDetail) error {
+	detail.TenantID = model.TenantFromContext(ctx)
+	return r.db.WithContext(ctx).Create(detail).Error
 }
 
-func (r *topicDetailRepository) FindAllByTopicID(topicID uint) ([]model.TopicDetail, error) {
+func (r *topicDetailRepository) FindAllByTopicID(ctx context.Context, topicID uint) ([]model.TopicDetail, error) {
 	var details []model.TopicDetail
-	err := r.db.Where("topic_id = ?", topicID).Order("[order] ASC").Find(&details).Error
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Where("topic_id = ?", topicID).Order("[order] ASC").Find(&details).Error
 	return details, err
 }
 
-func (r *topicDetailRepository) FindByID(id uint) (*model.TopicDetail, error) {
+func (r *topicDetailRepository) FindByID(ctx context.Context, id uint) (*model.TopicDetail, error) {
 	var detail model.TopicDetail
-	err := r.db.First(&detail, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&detail, "id = ?", id).Error
 	return &detail, err
 }
 
-func (r *topicDetailRepository) FindByName(name string) (*model.TopicDetail, error) {
+func (r *topicDetailRepository) FindByName(ctx context.Context, name string) (*model.TopicDetail, error) {
 	var detail model.TopicDetail
-	err := r.db.First(&detail, "name = ?", name).Error
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&detail, "name = ?", name).Error
 	return &detail, err
 }
 
-func (r *topicDetailRepository) Update(detail *model.TopicDetail) error {
-	return r.db.Save(detail).Error
+// Update saves all fields of the detail. Unlike Save it never falls back to an insert,
+// so a detail of another organization cannot be written.
+func (r *topicDetailRepository) Update(ctx context.Context, detail *model.TopicDetail) error {
+	return r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Select("*").Omit("Topic").Updates(detail).Error
 }
 
-func (r *topicDetailRepository) Delete(id uint) error {
-	return r.db.Delete(&model.TopicDetail{}, "id = ?", id).Error
+func (r *topicDetailRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Delete(&model.TopicDetail{}, "id = ?", id).Error
 } 
\ No newline at end of file
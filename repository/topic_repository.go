@@ -1,18 +1,21 @@
 package repository
 
 import (
+	"context"
+
 	"go-gin-gorm-backend/model"
 
 	"gorm.io/gorm"
 )
 
+// TopicRepository methods only see the topics of the organization in ctx
 type TopicRepository interface {
-	Create(topic *model.Topic) error
-	FindAll() ([]model.Topic, error)
-	FindByID(id uint) (*model.Topic, error)
-	FindByName(name string) (*model.Topic, error)
-	Update(topic *model.Topic) error
-	Delete(id uint) error
+	Create(ctx context.Context, topic *model.Topic) error
+	FindAll(ctx context.Context) ([]model.Topic, error)
+	FindByID(ctx context.Context, id uint) (*model.Topic, error)
+	FindByName(ctx context.Context, name string) (*model.Topic, error)
+	Update(ctx context.Context, topic *model.Topic) error
+	Delete(ctx context.Context, id uint) error
 }
 
 type topicRepository struct {
@@ -23,32 +26,42 @@ func NewTopicRepository(db *gorm.DB) TopicRepository {
 	return &topicRepository{db}
 }
 
-func (r *topicRepository) Create(topic *model.Topic) error {
-	return r.db.Create(topic).Error
+// Create stores the topic in the organization of ctx
+func (r *topicRepository) Create(ctx context.Context, topic *model.Topic) error {
+	topic.TenantID = model.TenantFromContext(ctx)
+	return r.db.WithContext(ctx).Create(topic).Error
 }
 
-func (r *topicRepository) FindAll() ([]model.Topic, error) {
+func (r *topicRepository) FindAll(ctx context.Context) ([]model.Topic, error) {
 	var topics []model.Topic
-	err := r.db.Order("[order] ASC").Find(&topics).Error
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Order("[order] ASC").Find(&topics).Error
 	return topics, err
 }
 
-func (r *topicRepository) FindByID(id uint) (*model.Topic, error) {
+func (r *topicRepository) FindByID(ctx context.Context, id uint) (*model.Topic, error) {
 	var topic model.Topic
-	err := r.db.First(&topic, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&topic, "id = ?", id).Error
 	return &topic, err
 }
 
-func (r *topicRepository) FindByName(name string) (*model.Topic, error) {
+func (r *topicRepository) FindByName(ctx context.Context, name string) (*model.Topic, error) {
 	var topic model.Topic
-	err := r.db.First(&topic, "name = ?", name).Error
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&topic, "name = ?", name).Error
 	return &topic, err
 }
 
-func (r *topicRepository) Update(topic *model.Topic) error {
-	return r.db.Save(topic).Error
+// Update saves all fields of the topic. Unlike Save it never falls back to an insert,
+// so a topic of another organization cannot be written.
+func (r *topicRepository) Update(ctx context.Context, topic *model.Topic) error {
+	return r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Select("*").Updates(topic).Error
 }
 
-func (r *topicRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Topic{}, "id = ?", id).Error
+// Delete removes the topic together with its access control list
+func (r *topicRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(TenantScope(ctx)).Where("topic_id = ?", id).Delete(&model.TopicACLEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Scopes(TenantScope(ctx)).Delete(&model.Topic{}, "id = ?", id).Error
+	})
 }
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"go-gin-gorm-backend/model"
 
 	"gorm.io/gorm"
@@ -14,24 +16,101 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// CreateUser creates a new user
+// CreateUser creates a new user and assigns the (existing) roles set on it
 func (r *UserRepository) CreateUser(user *model.User) error {
-	return r.db.Create(user).Error
+	return r.db.Omit("Roles.*").Create(user).Error
+}
+
+// FindAll gets all users of the caller's organization that have not been deleted
+func (r *UserRepository) FindAll(ctx context.Context) ([]model.User, error) {
+	var users []model.User
+	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Preload("Roles").Order("id ASC").Find(&users).Error
+	return users, err
 }
 
 // GetUserByUsername gets a user by username
 func (r *UserRepository) GetUserByUsername(username string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := r.db.Preload("Roles.Permissions").Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByEmail gets a user by email
+func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
+	var user model.User
+	err := r.db.Preload("Roles.Permissions").Where("email = ?", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// CheckUsernameExists checks if username already exists
+// GetUserByID gets a user by ID
+func (r *UserRepository) GetUserByID(id uint) (*model.User, error) {
+	var user model.User
+	err := r.db.Preload("Roles.Permissions").First(&user, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ExistsInTenant reports whether a user belongs to the caller's organization
+func (r *UserRepository) ExistsInTenant(ctx context.Context, id uint) bool {
+	var count int64
+	r.db.WithContext(ctx).Model(&model.User{}).Scopes(TenantScope(ctx)).Where("id = ?", id).Count(&count)
+	return count > 0
+}
+
+// UpdateUser writes the given columns of a user. Other columns are left alone, so a stale copy of the user
+// cannot undo concurrent changes such as an accepted TOTP step. Role assignments are changed with ReplaceUserRoles.
+func (r *UserRepository) UpdateUser(user *model.User, columns ...string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	return r.db.Model(user).Select(columns).Updates(user).Error
+}
+
+// UpdatePasswordHash replaces a password hash unless the password was changed in the meantime
+func (r *UserRepository) UpdatePasswordHash(id uint, oldHash, newHash string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ? AND password = ?", id, oldHash).
+		Update("password", newHash).Error
+}
+
+// AdvanceTwoFactorStep records the TOTP time step of an accepted code.
+// It reports false when a code of the same or a later step was already accepted, which means a replay.
+func (r *UserRepository) AdvanceTwoFactorStep(id uint, step int64) (bool, error) {
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND two_factor_last_step < ?", id, step).
+		Update("two_factor_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReplaceUserRoles replaces the roles assigned to a user
+func (r *UserRepository) ReplaceUserRoles(user *model.User, roles []model.Role) error {
+	return r.db.Model(user).Omit("Roles.*").Association("Roles").Replace(roles)
+}
+
+// DeleteUser soft-deletes a user
+func (r *UserRepository) DeleteUser(id uint) error {
+	return r.db.Delete(&model.User{}, "id = ?", id).Error
+}
+
+// CheckUsernameExists checks if username already exists.
+// Soft-deleted users are included because the unique index still covers them.
 func (r *UserRepository) CheckUsernameExists(username string) bool {
 	var count int64
-	r.db.Model(&model.User{}).Where("username = ?", username).Count(&count)
+	r.db.Unscoped().Model(&model.User{}).Where("username = ?", username).Count(&count)
+	return count > 0
+}
+
+// CheckEmailExists checks if email is already used by a user other than excludeID
+func (r *UserRepository) CheckEmailExists(email string, excludeID uint) bool {
+	var count int64
+	r.db.Unscoped().Model(&model.User{}).Where("email = ? AND id <> ?", email, excludeID).Count(&count)
 	return count > 0
 }
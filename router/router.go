@@ -1,8 +1,13 @@
 package router
 
 import (
+	"log"
+
+	"go-gin-gorm-backend/config"
 	"go-gin-gorm-backend/handler"
 	"go-gin-gorm-backend/middleware"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/service"
 
 	_ "go-gin-gorm-backend/docs" // This is generated by swag
 
@@ -11,43 +16,190 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter(topicHandler *handler.TopicHandler, topicDetailHandler *handler.TopicDetailHandler, authHandler *handler.AuthHandler) *gin.Engine {
-	r := gin.Default()
+func SetupRouter(topicHandler *handler.TopicHandler, topicDetailHandler *handler.TopicDetailHandler, authHandler *handler.AuthHandler, registrationHandler *handler.RegistrationHandler, profileHandler *handler.ProfileHandler, privacyHandler *handler.PrivacyHandler, oidcHandler *handler.OIDCHandler, twoFactorHandler *handler.TwoFactorHandler, userHandler *handler.UserHandler, roleHandler *handler.RoleHandler, apiKeyHandler *handler.APIKeyHandler, sessionHandler *handler.SessionHandler, organizationHandler *handler.OrganizationHandler, oauthHandler *handler.OAuthHandler, impersonationHandler *handler.ImpersonationHandler, invitationHandler *handler.InvitationHandler, teamHandler *handler.TeamHandler, securityEventHandler *handler.SecurityEventHandler, tokenService *service.TokenService, apiKeyService *service.APIKeyService, teamService *service.TeamService, userService *service.UserService) *gin.Engine {
+	r, err := newEngine(config.LoadServerConfig().TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
 	// Swagger documentation endpoint
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Public keys for verifying our tokens
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// Public routes (no authentication required)
 	auth := r.Group("/auth")
 	{
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/register", registrationHandler.Register)
+		auth.POST("/verify-email", registrationHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", registrationHandler.ResendVerification)
+		auth.POST("/invitations/accept", invitationHandler.AcceptInvitation)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.POST("/2fa/verify", twoFactorHandler.Verify)
+		auth.POST("/password/forgot", authHandler.ForgotPassword)
+		auth.POST("/password/reset", authHandler.ResetPassword)
+		auth.GET("/oidc/login", oidcHandler.Login)
+		auth.GET("/oidc/callback", oidcHandler.Callback)
 	}
 
+	// OAuth2 token endpoint (clients authenticate with their credentials)
+	r.POST("/oauth/token", oauthHandler.Token)
+
 	// Protected routes (authentication required)
 	protected := r.Group("/")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(tokenService, apiKeyService, teamService))
 	{
+		// Changing credentials or the profile is left to the user themselves, impersonation tokens are refused
+		ownerOnly := middleware.DenyImpersonation()
+
+		// Authenticated auth routes
+		protected.POST("/auth/logout", authHandler.Logout)
+		protected.POST("/auth/password", ownerOnly, authHandler.ChangePassword)
+		protected.GET("/auth/me", profileHandler.GetMe)
+		protected.PATCH("/auth/me", ownerOnly, profileHandler.UpdateMe)
+		protected.GET("/auth/me/export", privacyHandler.ExportMyData)
+		protected.POST("/auth/me/erase", ownerOnly, privacyHandler.EraseMe)
+		protected.GET("/auth/sessions", sessionHandler.GetMySessions)
+		protected.DELETE("/auth/sessions/:id", sessionHandler.RevokeMySession)
+		protected.POST("/auth/2fa/setup", ownerOnly, twoFactorHandler.Setup)
+		protected.POST("/auth/2fa/enable", ownerOnly, twoFactorHandler.Enable)
+		protected.POST("/auth/2fa/disable", ownerOnly, twoFactorHandler.Disable)
+		protected.POST("/auth/2fa/recovery-codes", ownerOnly, twoFactorHandler.RegenerateRecoveryCodes)
+
+		canRead := middleware.RequirePermission(model.PermissionTopicsRead)
+		canWrite := middleware.RequirePermission(model.PermissionTopicsWrite)
+
 		// Topic routes (protected)
 		topic := protected.Group("/topics")
 		{
-			topic.GET("", topicHandler.GetAllTopics)
-			topic.POST("", topicHandler.CreateTopic)
-			topic.GET(":id", topicHandler.GetTopicByID)
-			topic.PUT(":id", topicHandler.UpdateTopic)
-			topic.DELETE(":id", topicHandler.DeleteTopic)
+			topic.GET("", canRead, topicHandler.GetAllTopics)
+			topic.POST("", canWrite, topicHandler.CreateTopic)
+			topic.GET(":id", canRead, topicHandler.GetTopicByID)
+			topic.PUT(":id", canWrite, topicHandler.UpdateTopic)
+			topic.DELETE(":id", canWrite, topicHandler.DeleteTopic)
+			topic.GET(":id/acl", canWrite, topicHandler.GetTopicACL)
+			topic.PUT(":id/acl", canWrite, topicHandler.SetTopicACLEntry)
+			topic.DELETE(":id/acl/:entryId", canWrite, topicHandler.DeleteTopicACLEntry)
 
-			topic.GET(":id/details", topicDetailHandler.GetAllDetailsByTopicID)
-			topic.POST(":id/details", topicDetailHandler.CreateTopicDetail)
+			topic.GET(":id/details", canRead, topicDetailHandler.GetAllDetailsByTopicID)
+			topic.POST(":id/details", canWrite, topicDetailHandler.CreateTopicDetail)
 		}
 
 		// Detail routes (protected)
 		detail := protected.Group("/details")
 		{
-			detail.GET(":id", topicDetailHandler.GetDetailByID)
-			detail.PUT(":id", topicDetailHandler.UpdateTopicDetail)
-			detail.DELETE(":id", topicDetailHandler.DeleteTopicDetail)
+			detail.GET(":id", canRead, topicDetailHandler.GetDetailByID)
+			detail.PUT(":id", canWrite, topicDetailHandler.UpdateTopicDetail)
+			detail.DELETE(":id", canWrite, topicDetailHandler.DeleteTopicDetail)
+		}
+
+		// User management routes (admin only, limited to the admin's organization)
+		user := protected.Group("/users")
+		user.Use(middleware.AdminMiddleware(), middleware.TenantUserMiddleware(userService))
+		{
+			user.GET("", userHandler.GetAllUsers)
+			user.POST("", middleware.DenyAPIKey(), userHandler.CreateUser)
+			user.GET(":id", userHandler.GetUserByID)
+			user.PUT(":id", userHandler.UpdateUser)
+			user.DELETE(":id", userHandler.DeleteUser)
+			user.POST(":id/deactivate", userHandler.DeactivateUser)
+			user.POST(":id/activate", userHandler.ActivateUser)
+			user.POST(":id/approve", registrationHandler.ApproveUser)
+			user.POST(":id/revoke-sessions", userHandler.RevokeUserSessions)
+			user.GET(":id/sessions", sessionHandler.GetUserSessions)
+			user.DELETE(":id/sessions/:sessionId", sessionHandler.RevokeUserSession)
+			user.POST(":id/unlock", userHandler.UnlockUser)
+			user.POST(":id/2fa/reset", twoFactorHandler.ResetUserTwoFactor)
+			user.GET(":id/export", privacyHandler.ExportUserData)
+			user.POST(":id/erase", privacyHandler.EraseUser)
+			user.POST(":id/impersonate", ownerOnly, middleware.DenyAPIKey(), impersonationHandler.ImpersonateUser)
+		}
+
+		// Team routes (admin only, limited to the admin's organization)
+		team := protected.Group("/teams")
+		team.Use(middleware.AdminMiddleware())
+		{
+			team.GET("", teamHandler.GetAllTeams)
+			team.POST("", teamHandler.CreateTeam)
+			team.GET(":id", teamHandler.GetTeamByID)
+			team.PUT(":id", teamHandler.UpdateTeam)
+			team.DELETE(":id", teamHandler.DeleteTeam)
+			team.PUT(":id/members/:userId", teamHandler.AddTeamMember)
+			team.DELETE(":id/members/:userId", teamHandler.RemoveTeamMember)
+		}
+
+		// Invitation routes (admin only, limited to the admin's organization)
+		invitation := protected.Group("/invitations")
+		invitation.Use(middleware.AdminMiddleware())
+		{
+			invitation.GET("", invitationHandler.GetAllInvitations)
+			invitation.POST("", middleware.DenyAPIKey(), invitationHandler.CreateInvitation)
+			invitation.POST(":id/resend", middleware.DenyAPIKey(), invitationHandler.ResendInvitation)
+			invitation.DELETE(":id", invitationHandler.RevokeInvitation)
+		}
+
+		// Role routes (admin only)
+		role := protected.Group("/roles")
+		role.Use(middleware.AdminMiddleware())
+		{
+			role.GET("", roleHandler.GetAllRoles)
+			role.POST("", roleHandler.CreateRole)
+			role.GET(":id", roleHandler.GetRoleByID)
+			role.PUT(":id", roleHandler.UpdateRole)
+			role.DELETE(":id", roleHandler.DeleteRole)
+		}
+		protected.GET("/permissions", middleware.AdminMiddleware(), roleHandler.GetAllPermissions)
+
+		// Organization routes (platform admins only)
+		organization := protected.Group("/organizations")
+		organization.Use(middleware.RequirePermission(model.PermissionOrganizationsManage))
+		{
+			organization.GET("", organizationHandler.GetAllOrganizations)
+			organization.POST("", organizationHandler.CreateOrganization)
+			organization.GET(":id", organizationHandler.GetOrganizationByID)
+			organization.PUT(":id", organizationHandler.UpdateOrganization)
+		}
+
+		// OAuth client routes (admin only)
+		oauthClient := protected.Group("/oauth/clients")
+		oauthClient.Use(middleware.AdminMiddleware())
+		{
+			oauthClient.GET("", oauthHandler.GetAllClients)
+			oauthClient.POST("", middleware.DenyAPIKey(), oauthHandler.CreateClient)
+			oauthClient.DELETE(":id", oauthHandler.RevokeClient)
+		}
+
+		// Impersonation routes (admin only)
+		impersonation := protected.Group("/impersonations")
+		impersonation.Use(middleware.AdminMiddleware(), ownerOnly)
+		{
+			impersonation.GET("", impersonationHandler.GetAllImpersonations)
+			impersonation.DELETE(":id", impersonationHandler.EndImpersonation)
+		}
+
+		// Security event log (admin only)
+		protected.GET("/security-events", middleware.AdminMiddleware(), securityEventHandler.GetSecurityEvents)
+
+		// API key routes (own keys, or all keys with api_keys:manage)
+		apiKey := protected.Group("/api-keys")
+		{
+			apiKey.GET("", apiKeyHandler.GetAllAPIKeys)
+			apiKey.POST("", ownerOnly, middleware.DenyAPIKey(), apiKeyHandler.CreateAPIKey)
+			apiKey.DELETE(":id", apiKeyHandler.RevokeAPIKey)
 		}
 	}
 
 	return r
 }
+
+// newEngine creates the gin engine. Login throttling, sessions and security events go by the client IP,
+// so X-Forwarded-For is only honoured on requests coming from one of the trusted proxies.
+func newEngine(trustedProxies []string) (*gin.Engine, error) {
+	r := gin.Default()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
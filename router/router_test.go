@@ -0,0 +1,78 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientIPIgnoresForgedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		headers        map[string]string
+		want           string
+	}{
+		{
+			name:       "forged X-Forwarded-For without trusted proxies",
+			remoteAddr: "203.0.113.10:51234",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.7"},
+			want:       "203.0.113.10",
+		},
+		{
+			name:       "forged X-Real-IP without trusted proxies",
+			remoteAddr: "203.0.113.10:51234",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.7"},
+			want:       "203.0.113.10",
+		},
+		{
+			name:           "forged X-Forwarded-For from a client that is not a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "203.0.113.10:51234",
+			headers:        map[string]string{"X-Forwarded-For": "198.51.100.7"},
+			want:           "203.0.113.10",
+		},
+		{
+			name:           "X-Forwarded-For set by a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.5:51234",
+			headers:        map[string]string{"X-Forwarded-For": "198.51.100.7"},
+			want:           "198.51.100.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newEngine(tt.trustedProxies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.GET("/ip", func(c *gin.Context) {
+				c.String(http.StatusOK, c.ClientIP())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("client IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidProxies(t *testing.T) {
+	if _, err := newEngine([]string{"not-an-address"}); err == nil {
+		t.Error("newEngine() accepted an invalid trusted proxy")
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+const (
+	apiKeyPrefix = "ggb_"
+	// apiKeyPrefixLen is the length of the identifying part, e.g. "ggb_1a2b3c4d"
+	apiKeyPrefixLen = len(apiKeyPrefix) + 8
+	// apiKeyLastUsedInterval limits how often the last used timestamp is written
+	apiKeyLastUsedInterval = time.Minute
+)
+
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   *repository.UserRepository
+	roleRepo   repository.RoleRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, userRepo *repository.UserRepository, roleRepo repository.RoleRepository) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo, userRepo: userRepo, roleRepo: roleRepo}
+}
+
+// CreateAPIKey creates a key owned by the caller, or by req.UserID of the same organization when the caller
+// may manage all keys. A key can only be scoped to permissions the caller holds, and keys for other users
+// must be scoped, so no key grants more than its creator has. The returned response holds the only copy of the plain key.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, callerID uint, callerPermissions []string, canManageAll bool, req *model.CreateAPIKeyRequest) (*model.CreateAPIKeyResponse, error) {
+	ownerID := callerID
+	if req.UserID != nil && *req.UserID != callerID {
+		if !canManageAll {
+			return nil, errors.New("insufficient permissions")
+		}
+		if len(req.Scopes) == 0 {
+			return nil, errors.New("scopes are required for keys of other users")
+		}
+		ownerID = *req.UserID
+	}
+
+	for _, scope := range req.Scopes {
+		if !slices.Contains(callerPermissions, scope) {
+			return nil, errors.New("insufficient permissions")
+		}
+	}
+
+	if !s.userRepo.ExistsInTenant(ctx, ownerID) {
+		return nil, errors.New("user not found")
+	}
+
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	if len(req.Scopes) > 0 {
+		permissions, err := s.roleRepo.FindPermissionsByNames(req.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		for _, scope := range req.Scopes {
+			if !slices.ContainsFunc(permissions, func(p model.Permission) bool { return p.Name == scope }) {
+				return nil, errors.New("permission not found")
+			}
+		}
+	}
+
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return nil, err
+	}
+	prefix := apiKeyPrefix + hex.EncodeToString(prefixBytes)
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	plainKey := prefix + "_" + secret
+
+	key := &model.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(plainKey),
+		UserID:    ownerID,
+		Scopes:    strings.Join(req.Scopes, " "),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+
+	return &model.CreateAPIKeyResponse{
+		APIKeyResponse: key.ToAPIKeyResponse(),
+		Key:            plainKey,
+	}, nil
+}
+
+// ListAPIKeys returns the keys of the caller, or of every user in the organization when the caller may manage all keys
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, callerID uint, canManageAll bool) ([]model.APIKeyResponse, error) {
+	var keys []model.APIKey
+	var err error
+	if canManageAll {
+		keys, err = s.apiKeyRepo.FindAll(ctx)
+	} else {
+		keys, err = s.apiKeyRepo.FindAllByUserID(callerID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, key.ToAPIKeyResponse())
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey revokes a key owned by the caller, or any key in the organization when the caller may manage all keys
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uint, callerID uint, canManageAll bool) error {
+	key, err := s.apiKeyRepo.FindByID(id)
+	if err != nil || (key.UserID != callerID && (!canManageAll || !s.userRepo.ExistsInTenant(ctx, key.UserID))) {
+		return errors.New("api key not found")
+	}
+
+	return s.apiKeyRepo.Revoke(key.ID, time.Now())
+}
+
+// Authenticate resolves an API key to claims for its owner.
+// When the key has scopes, the owner's permissions are narrowed down to them.
+func (s *APIKeyService) Authenticate(plainKey string) (*model.Claims, error) {
+	if len(plainKey) <= apiKeyPrefixLen || !strings.HasPrefix(plainKey, apiKeyPrefix) {
+		return nil, errors.New("invalid api key")
+	}
+
+	key, err := s.apiKeyRepo.FindByPrefix(plainKey[:apiKeyPrefixLen])
+	if err != nil || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(utils.HashToken(plainKey))) != 1 {
+		return nil, errors.New("invalid api key")
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, errors.New("api key has been revoked")
+	}
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, errors.New("api key expired")
+	}
+
+	user, err := s.userRepo.GetUserByID(key.UserID)
+	if err != nil || !user.IsActive {
+		return nil, errors.New("invalid api key")
+	}
+
+	// A scoped key carries no roles, so role-gated routes such as the admin ones stay closed to it
+	roles := user.RoleNames()
+	permissions := user.EffectivePermissions()
+	if scopes := key.ScopeList(); len(scopes) > 0 {
+		roles = nil
+		permissions = slices.DeleteFunc(permissions, func(p string) bool { return !slices.Contains(scopes, p) })
+	}
+
+	// Service accounts cannot enroll in 2FA, so only keys of regular users wait for the enrollment
+	twoFactorSetup := !user.IsServiceAccount && user.RequiresTwoFactor() && !user.TwoFactorEnabled
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyLastUsedInterval {
+		if err := s.apiKeyRepo.UpdateLastUsed(key.ID, now); err != nil {
+			log.Printf("Error updating last used time of api key %d: %v", key.ID, err)
+		}
+	}
+
+	return &model.Claims{
+		UserID:             user.ID,
+		Username:           user.Username,
+		Roles:              roles,
+		Permissions:        permissions,
+		TenantID:           user.TenantID,
+		MustChangePassword: user.MustChangePassword,
+		TwoFactorSetup:     twoFactorSetup,
+		Scope:              key.Scopes, // also limits the permissions granted through teams
+	}, nil
+}
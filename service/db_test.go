@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a dry-run database: statements are built but never sent to a server. Reads find
+// nothing and writes affect no rows unless the test plays the database with onQuery or onUpdate.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlserver.Open("sqlserver://localhost"), &gorm.Config{DryRun: true, SkipDefaultTransaction: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// onQuery calls play after every query on db, for example to fill tx.Statement.Dest with a row.
+// It runs after preloading, which would otherwise reset the associations play fills in.
+func onQuery(t *testing.T, db *gorm.DB, play func(tx *gorm.DB)) {
+	t.Helper()
+	if err := db.Callback().Query().After("gorm:after_query").Register("test:play_query", play); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// onUpdate calls play after every update on db, for example to set tx.RowsAffected
+func onUpdate(t *testing.T, db *gorm.DB, play func(tx *gorm.DB)) {
+	t.Helper()
+	if err := db.Callback().Update().After("gorm:update").Register("test:play_update", play); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// onCreate calls play after every insert on db, for example to assign the ID or fail like a unique index
+func onCreate(t *testing.T, db *gorm.DB, play func(tx *gorm.DB)) {
+	t.Helper()
+	if err := db.Callback().Create().After("gorm:create").Register("test:play_create", play); err != nil {
+		t.Fatal(err)
+	}
+}
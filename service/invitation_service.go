@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/mailer"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+type InvitationService struct {
+	cfg              *config.InvitationConfig
+	invitationRepo   repository.InvitationRepository
+	userRepo         *repository.UserRepository
+	roleRepo         repository.RoleRepository
+	teamRepo         repository.TeamRepository
+	organizationRepo repository.OrganizationRepository
+	mailer           mailer.Mailer
+	linkBaseURL      string
+}
+
+func NewInvitationService(cfg *config.InvitationConfig, invitationRepo repository.InvitationRepository, userRepo *repository.UserRepository, roleRepo repository.RoleRepository, teamRepo repository.TeamRepository, organizationRepo repository.OrganizationRepository, m mailer.Mailer, linkBaseURL string) *InvitationService {
+	return &InvitationService{
+		cfg:              cfg,
+		invitationRepo:   invitationRepo,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		teamRepo:         teamRepo,
+		organizationRepo: organizationRepo,
+		mailer:           m,
+		linkBaseURL:      linkBaseURL,
+	}
+}
+
+// CreateInvitation invites someone by email to the caller's organization, or to another organization
+// with the organizations:manage permission, and mails them a one-time link
+func (s *InvitationService) CreateInvitation(ctx context.Context, req *model.CreateInvitationRequest, canManageOrganizations bool) (*model.InvitationResponse, error) {
+	tenantID := model.TenantFromContext(ctx)
+	if req.TenantID != nil && *req.TenantID != tenantID {
+		if !canManageOrganizations {
+			return nil, errors.New("insufficient permissions")
+		}
+		if _, err := s.organizationRepo.FindByID(*req.TenantID); err != nil {
+			return nil, errors.New("organization not found")
+		}
+		tenantID = *req.TenantID
+	}
+
+	if s.userRepo.CheckEmailExists(req.Email, 0) {
+		return nil, errors.New("email already exists")
+	}
+	if s.invitationRepo.ExistsPending(req.Email, time.Now()) {
+		return nil, errors.New("email has already been invited")
+	}
+
+	roleName := req.Role
+	if roleName == "" {
+		roleName = model.RoleUser
+	}
+	roles, err := s.roleRepo.FindByNames([]string{roleName})
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, errors.New("role not found")
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(roles[0].Permissions) {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	// Teams belong to one organization, so only teams of the admin's own organization can be picked
+	var teamID *uint
+	if req.TeamID != nil {
+		team, err := s.teamRepo.FindByID(ctx, *req.TeamID)
+		if err != nil || team.TenantID != tenantID {
+			return nil, errors.New("team not found")
+		}
+		teamID = &team.ID
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &model.Invitation{
+		TenantID:  tenantID,
+		Email:     req.Email,
+		FullName:  req.FullName,
+		RoleID:    roles[0].ID,
+		Role:      roles[0],
+		TeamID:    teamID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.TTL),
+		InvitedBy: model.ActorFromContext(ctx),
+	}
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	s.sendInvitation(invitation, token)
+
+	log.Printf("User %s invited %s as %s", invitation.InvitedBy, invitation.Email, roleName)
+	response := invitation.ToInvitationResponse()
+	return &response, nil
+}
+
+// ListInvitations returns the invitations of the caller's organization
+func (s *InvitationService) ListInvitations(ctx context.Context) ([]model.InvitationResponse, error) {
+	invitations, err := s.invitationRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, invitation.ToInvitationResponse())
+	}
+	return responses, nil
+}
+
+// ResendInvitation mails a new link for an open invitation and restarts its expiry.
+// The previously mailed link stops working.
+func (s *InvitationService) ResendInvitation(ctx context.Context, id uint) (*model.InvitationResponse, error) {
+	invitation, err := s.invitationRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("invitation not found")
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.cfg.TTL)
+	renewed, err := s.invitationRepo.Renew(invitation.ID, utils.HashToken(token), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !renewed {
+		return nil, errors.New("invitation is no longer open")
+	}
+	invitation.ExpiresAt = expiresAt
+
+	s.sendInvitation(invitation, token)
+
+	response := invitation.ToInvitationResponse()
+	return &response, nil
+}
+
+// RevokeInvitation withdraws an open invitation so its link stops working
+func (s *InvitationService) RevokeInvitation(ctx context.Context, id uint) error {
+	invitation, err := s.invitationRepo.FindByID(ctx, id)
+	if err != nil {
+		return errors.New("invitation not found")
+	}
+
+	revoked, err := s.invitationRepo.Revoke(invitation.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("invitation is no longer open")
+	}
+	return nil
+}
+
+// AcceptInvitation creates the invitee's account with the invited role and team and the password they chose.
+// Opening the mailed link proves the address, so the account is verified and active right away.
+func (s *InvitationService) AcceptInvitation(req *model.AcceptInvitationRequest) (*model.UserResponse, error) {
+	invitation, err := s.invitationRepo.FindByTokenHash(utils.HashToken(req.Token))
+	if err != nil || invitation.Status(time.Now()) != model.InvitationPending {
+		return nil, errors.New("invalid or expired invitation")
+	}
+
+	if s.userRepo.CheckUsernameExists(req.Username) {
+		return nil, errors.New("username already exists")
+	}
+	if s.userRepo.CheckEmailExists(invitation.Email, 0) {
+		return nil, errors.New("email already exists")
+	}
+	if err := config.ValidatePassword(req.Password, req.Username); err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.FindByID(invitation.RoleID)
+	if err != nil {
+		return nil, errors.New("role not found")
+	}
+
+	hashedPassword, err := config.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := req.FullName
+	if fullName == "" {
+		fullName = invitation.FullName
+	}
+	if fullName == "" {
+		fullName = req.Username
+	}
+
+	// Consume the invitation first so the link cannot create two accounts
+	now := time.Now()
+	accepted, err := s.invitationRepo.MarkAccepted(invitation.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, errors.New("invalid or expired invitation")
+	}
+
+	user := &model.User{
+		Username:        req.Username,
+		Email:           invitation.Email,
+		Password:        hashedPassword,
+		FullName:        fullName,
+		Roles:           []model.Role{*role},
+		TenantID:        invitation.TenantID,
+		IsActive:        true,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.userRepo.CreateUser(user); err != nil {
+		if reopenErr := s.invitationRepo.Reopen(invitation.ID); reopenErr != nil {
+			log.Printf("Error reopening invitation %d: %v", invitation.ID, reopenErr)
+		}
+		return nil, handleDuplicateUserError(err)
+	}
+
+	if err := s.invitationRepo.SetUserID(invitation.ID, user.ID); err != nil {
+		log.Printf("Error linking invitation %d to user %d: %v", invitation.ID, user.ID, err)
+	}
+	if invitation.TeamID != nil {
+		if err := s.teamRepo.AddMember(*invitation.TeamID, user.ID); err != nil {
+			log.Printf("Error adding user %d to team %d: %v", user.ID, *invitation.TeamID, err)
+		}
+	}
+
+	log.Printf("User %s accepted the invitation %d", user.Username, invitation.ID)
+	response := user.ToUserResponse()
+	return &response, nil
+}
+
+// sendInvitation mails the one-time invitation link. Delivery failures are logged; the admin can resend.
+func (s *InvitationService) sendInvitation(invitation *model.Invitation, token string) {
+	greeting := "Hello"
+	if invitation.FullName != "" {
+		greeting += " " + invitation.FullName
+	}
+
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", s.linkBaseURL, url.QueryEscape(token))
+	if err := s.mailer.Send(mailer.Message{
+		To:      invitation.Email,
+		Subject: "You have been invited",
+		Body: fmt.Sprintf("%s,\n\n%s invited you to create an account. Use the link below to choose your username and password. The link expires in %s and can only be used once.\n\n%s\n\nIf you did not expect this invitation you can ignore this mail.\n",
+			greeting, invitation.InvitedBy, s.cfg.TTL, link),
+	}); err != nil {
+		log.Printf("Error sending invitation mail for invitation %d: %v", invitation.ID, err)
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeInvitationRepository keeps invitations in memory. Renew and MarkAccepted only change open
+// invitations, like the conditional updates of the database repository.
+type fakeInvitationRepository struct {
+	invitations []*model.Invitation
+}
+
+func (r *fakeInvitationRepository) Create(invitation *model.Invitation) error {
+	invitation.ID = uint(len(r.invitations) + 1)
+	r.invitations = append(r.invitations, invitation)
+	return nil
+}
+
+func (r *fakeInvitationRepository) FindAll(ctx context.Context) ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	for _, invitation := range r.invitations {
+		if invitation.TenantID == model.TenantFromContext(ctx) {
+			invitations = append(invitations, *invitation)
+		}
+	}
+	return invitations, nil
+}
+
+func (r *fakeInvitationRepository) FindByID(ctx context.Context, id uint) (*model.Invitation, error) {
+	for _, invitation := range r.invitations {
+		if invitation.ID == id && invitation.TenantID == model.TenantFromContext(ctx) {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeInvitationRepository) FindByTokenHash(tokenHash string) (*model.Invitation, error) {
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeInvitationRepository) ExistsPending(email string, now time.Time) bool {
+	for _, invitation := range r.invitations {
+		if strings.EqualFold(invitation.Email, email) && invitation.Status(now) == model.InvitationPending {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeInvitationRepository) Renew(id uint, tokenHash string, expiresAt time.Time) (bool, error) {
+	for _, invitation := range r.invitations {
+		if invitation.ID == id && invitation.AcceptedAt == nil && invitation.RevokedAt == nil {
+			invitation.TokenHash = tokenHash
+			invitation.ExpiresAt = expiresAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeInvitationRepository) Revoke(id uint, revokedAt time.Time) (bool, error) {
+	for _, invitation := range r.invitations {
+		if invitation.ID == id && invitation.AcceptedAt == nil && invitation.RevokedAt == nil {
+			invitation.RevokedAt = &revokedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeInvitationRepository) MarkAccepted(id uint, acceptedAt time.Time) (bool, error) {
+	for _, invitation := range r.invitations {
+		if invitation.ID == id && invitation.Status(acceptedAt) == model.InvitationPending {
+			invitation.AcceptedAt = &acceptedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeInvitationRepository) Reopen(id uint) error {
+	for _, invitation := range r.invitations {
+		if invitation.ID == id {
+			invitation.AcceptedAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeInvitationRepository) SetUserID(id uint, userID uint) error {
+	for _, invitation := range r.invitations {
+		if invitation.ID == id {
+			invitation.UserID = &userID
+		}
+	}
+	return nil
+}
+
+// fakeTeamRepository knows the teams of the organization in ctx and records the members added
+type fakeTeamRepository struct {
+	repository.TeamRepository
+	teams   []model.Team
+	members map[uint][]uint
+}
+
+func (r *fakeTeamRepository) FindByID(ctx context.Context, id uint) (*model.Team, error) {
+	for _, team := range r.teams {
+		if team.ID == id && team.TenantID == model.TenantFromContext(ctx) {
+			return &team, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTeamRepository) AddMember(teamID, userID uint) error {
+	if r.members == nil {
+		r.members = make(map[uint][]uint)
+	}
+	r.members[teamID] = append(r.members[teamID], userID)
+	return nil
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	pharmacy := uint(5)
+	accept := func(token, username string) *model.AcceptInvitationRequest {
+		return &model.AcceptInvitationRequest{Token: token, Username: username, Password: "Str0ng-Passw0rd"}
+	}
+
+	tests := []struct {
+		name string
+		// use accepts the invitation with the token of the first mail; the error of the last call is returned
+		use func(s *InvitationService, invitationRepo *fakeInvitationRepository, token string) (*model.UserResponse, error)
+		// takenUsername makes inserting a user with that name fail like the unique index would
+		takenUsername string
+		wantErr       string
+		wantAccepted  bool
+	}{
+		{
+			name: "open invitation",
+			use: func(s *InvitationService, _ *fakeInvitationRepository, token string) (*model.UserResponse, error) {
+				return s.AcceptInvitation(accept(token, "jane_doe"))
+			},
+			wantAccepted: true,
+		},
+		{
+			name: "link used a second time",
+			use: func(s *InvitationService, _ *fakeInvitationRepository, token string) (*model.UserResponse, error) {
+				if _, err := s.AcceptInvitation(accept(token, "jane_doe")); err != nil {
+					return nil, err
+				}
+				return s.AcceptInvitation(accept(token, "jane_doe2"))
+			},
+			wantErr:      "invalid or expired invitation",
+			wantAccepted: true,
+		},
+		{
+			name: "link replaced by a resend",
+			use: func(s *InvitationService, _ *fakeInvitationRepository, token string) (*model.UserResponse, error) {
+				ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: 1, Username: "admin", TenantID: 1})
+				if _, err := s.ResendInvitation(ctx, 1); err != nil {
+					return nil, err
+				}
+				return s.AcceptInvitation(accept(token, "jane_doe"))
+			},
+			wantErr: "invalid or expired invitation",
+		},
+		{
+			name: "revoked invitation",
+			use: func(s *InvitationService, _ *fakeInvitationRepository, token string) (*model.UserResponse, error) {
+				ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: 1, Username: "admin", TenantID: 1})
+				if err := s.RevokeInvitation(ctx, 1); err != nil {
+					return nil, err
+				}
+				return s.AcceptInvitation(accept(token, "jane_doe"))
+			},
+			wantErr: "invalid or expired invitation",
+		},
+		{
+			name: "expired invitation",
+			use: func(s *InvitationService, invitationRepo *fakeInvitationRepository, token string) (*model.UserResponse, error) {
+				invitationRepo.invitations[0].ExpiresAt = time.Now().Add(-time.Minute)
+				return s.AcceptInvitation(accept(token, "jane_doe"))
+			},
+			wantErr: "invalid or expired invitation",
+		},
+		{
+			name: "unknown token",
+			use: func(s *InvitationService, _ *fakeInvitationRepository, token string) (*model.UserResponse, error) {
+				return s.AcceptInvitation(accept(token+"x", "jane_doe"))
+			},
+			wantErr: "invalid or expired invitation",
+		},
+		{
+			name: "username taken while accepting",
+			use: func(s *InvitationService, _ *fakeInvitationRepository, token string) (*model.UserResponse, error) {
+				return s.AcceptInvitation(accept(token, "somchai"))
+			},
+			takenUsername: "somchai",
+			wantErr:       "username already exists",
+		},
+		{
+			name: "another username after a taken one",
+			use: func(s *InvitationService, _ *fakeInvitationRepository, token string) (*model.UserResponse, error) {
+				if _, err := s.AcceptInvitation(accept(token, "somchai")); err == nil {
+					return nil, errors.New("taken username accepted")
+				}
+				return s.AcceptInvitation(accept(token, "jane_doe"))
+			},
+			takenUsername: "somchai",
+			wantAccepted:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []*model.User
+			db := newTestDB(t)
+			onCreate(t, db, func(tx *gorm.DB) {
+				user, ok := tx.Statement.Dest.(*model.User)
+				if !ok {
+					return
+				}
+				if user.Username == tt.takenUsername {
+					tx.AddError(errors.New("mssql: Cannot insert duplicate key row in object 'dbo.users' with unique index 'idx_users_username'"))
+					return
+				}
+				user.ID = uint(10 + len(created))
+				created = append(created, user)
+			})
+
+			invitationRepo := &fakeInvitationRepository{}
+			roleRepo := &fakeRoleRepository{roles: []model.Role{{ID: 2, Name: model.RoleUser}}}
+			teamRepo := &fakeTeamRepository{teams: []model.Team{{ID: pharmacy, TenantID: 1, Name: "pharmacy"}}}
+			mail := &fakeMailer{}
+			s := NewInvitationService(&config.InvitationConfig{TTL: time.Hour}, invitationRepo, repository.NewUserRepository(db), roleRepo, teamRepo, nil, mail, "http://localhost:3000")
+
+			ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: 1, Username: "admin", TenantID: 1})
+			if _, err := s.CreateInvitation(ctx, &model.CreateInvitationRequest{Email: "jane@example.com", FullName: "Jane Doe", TeamID: &pharmacy}, false); err != nil {
+				t.Fatal(err)
+			}
+
+			user, err := tt.use(s, invitationRepo, tokenFromMail(t, mail.sent[0]))
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("AcceptInvitation() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("AcceptInvitation() error = %v", err)
+				}
+				if user.Username != "jane_doe" || user.Email != "jane@example.com" || user.FullName != "Jane Doe" || user.TenantID != 1 || !user.IsActive {
+					t.Errorf("user = %+v", user)
+				}
+				if len(created) != 1 || created[0].EmailVerifiedAt == nil || len(created[0].Roles) != 1 || created[0].Roles[0].Name != model.RoleUser {
+					t.Errorf("created users = %+v, want one verified user with the invited role", created)
+				}
+				if members := teamRepo.members[pharmacy]; len(members) != 1 || members[0] != user.ID {
+					t.Errorf("team members = %v, want [%d]", members, user.ID)
+				}
+			}
+
+			if accepted := invitationRepo.invitations[0].AcceptedAt != nil; accepted != tt.wantAccepted {
+				t.Errorf("invitation accepted = %v, want %v", accepted, tt.wantAccepted)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/repository"
+
+	"gorm.io/gorm"
+)
+
+type LoginThrottleService struct {
+	throttleRepo repository.LoginThrottleRepository
+	cfg          *config.LoginThrottleConfig
+}
+
+func NewLoginThrottleService(throttleRepo repository.LoginThrottleRepository, cfg *config.LoginThrottleConfig) *LoginThrottleService {
+	return &LoginThrottleService{throttleRepo: throttleRepo, cfg: cfg}
+}
+
+func userThrottleKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// CheckLogin returns an error when the username or the client IP is currently locked out.
+// It runs before the password is checked so locked attempts never cost a bcrypt comparison.
+func (s *LoginThrottleService) CheckLogin(username, clientIP string) error {
+	locked, err := s.isLocked(userThrottleKey(username))
+	if err != nil {
+		return err
+	}
+	if locked {
+		return errors.New("account temporarily locked")
+	}
+
+	locked, err = s.isLocked(ipThrottleKey(clientIP))
+	if err != nil {
+		return err
+	}
+	if locked {
+		return errors.New("too many failed login attempts")
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed login for both the username and the client IP
+func (s *LoginThrottleService) RecordFailure(username, clientIP string) error {
+	if err := s.recordFailure(userThrottleKey(username), s.cfg.MaxAttemptsPerUser); err != nil {
+		return err
+	}
+	return s.recordFailure(ipThrottleKey(clientIP), s.cfg.MaxAttemptsPerIP)
+}
+
+// RecordSuccess forgets the failed attempts of a username after a successful login
+func (s *LoginThrottleService) RecordSuccess(username string) error {
+	return s.throttleRepo.DeleteByKey(userThrottleKey(username))
+}
+
+// Unlock lifts the lockout of a username
+func (s *LoginThrottleService) Unlock(username string) error {
+	return s.throttleRepo.DeleteByKey(userThrottleKey(username))
+}
+
+func (s *LoginThrottleService) isLocked(key string) (bool, error) {
+	throttle, err := s.throttleRepo.FindByKey(key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return throttle.LockedUntil != nil && time.Now().Before(*throttle.LockedUntil), nil
+}
+
+func (s *LoginThrottleService) recordFailure(key string, maxAttempts int) error {
+	now := time.Now()
+	failedAttempts, err := s.throttleRepo.RecordFailure(key, now, now.Add(-s.cfg.AttemptWindow))
+	if err != nil {
+		return err
+	}
+
+	if lockout := s.lockoutDuration(failedAttempts, maxAttempts); lockout > 0 {
+		return s.throttleRepo.ExtendLockout(key, now.Add(lockout))
+	}
+	return nil
+}
+
+// lockoutDuration returns how long a key is locked after the given number of failed attempts, or 0.
+// The lock starts once the threshold is reached and doubles for every further failure.
+func (s *LoginThrottleService) lockoutDuration(failedAttempts, maxAttempts int) time.Duration {
+	if maxAttempts <= 0 || failedAttempts < maxAttempts {
+		return 0
+	}
+
+	lockout := s.cfg.LockoutDuration
+	for i := maxAttempts; i < failedAttempts && lockout < s.cfg.MaxLockoutDuration; i++ {
+		lockout *= 2
+	}
+	if lockout > s.cfg.MaxLockoutDuration {
+		lockout = s.cfg.MaxLockoutDuration
+	}
+	return lockout
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// fakeLoginThrottleRepository keeps throttles in memory and counts failures like the database MERGE does
+type fakeLoginThrottleRepository struct {
+	throttles map[string]*model.LoginThrottle
+}
+
+func (r *fakeLoginThrottleRepository) FindByKey(key string) (*model.LoginThrottle, error) {
+	throttle, ok := r.throttles[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return throttle, nil
+}
+
+func (r *fakeLoginThrottleRepository) RecordFailure(key string, now, windowStart time.Time) (int, error) {
+	throttle, ok := r.throttles[key]
+	if !ok {
+		throttle = &model.LoginThrottle{Key: key}
+		r.throttles[key] = throttle
+	}
+	if throttle.LastFailedAt.Before(windowStart) {
+		throttle.FailedAttempts = 0
+	}
+	throttle.FailedAttempts++
+	throttle.LastFailedAt = now
+	return throttle.FailedAttempts, nil
+}
+
+func (r *fakeLoginThrottleRepository) ExtendLockout(key string, lockedUntil time.Time) error {
+	throttle := r.throttles[key]
+	if throttle.LockedUntil == nil || throttle.LockedUntil.Before(lockedUntil) {
+		throttle.LockedUntil = &lockedUntil
+	}
+	return nil
+}
+
+func (r *fakeLoginThrottleRepository) DeleteByKey(key string) error {
+	delete(r.throttles, key)
+	return nil
+}
+
+func TestLockoutDuration(t *testing.T) {
+	cfg := &config.LoginThrottleConfig{LockoutDuration: 15 * time.Minute, MaxLockoutDuration: time.Hour}
+
+	tests := []struct {
+		name           string
+		failedAttempts int
+		maxAttempts    int
+		want           time.Duration
+	}{
+		{name: "below the threshold", failedAttempts: 4, maxAttempts: 5, want: 0},
+		{name: "at the threshold", failedAttempts: 5, maxAttempts: 5, want: 15 * time.Minute},
+		{name: "one more failure doubles the lockout", failedAttempts: 6, maxAttempts: 5, want: 30 * time.Minute},
+		{name: "capped at the maximum", failedAttempts: 8, maxAttempts: 5, want: time.Hour},
+		{name: "far beyond the threshold", failedAttempts: 500, maxAttempts: 5, want: time.Hour},
+		{name: "throttling disabled", failedAttempts: 50, maxAttempts: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewLoginThrottleService(nil, cfg)
+			if got := s.lockoutDuration(tt.failedAttempts, tt.maxAttempts); got != tt.want {
+				t.Errorf("lockoutDuration(%d, %d) = %v, want %v", tt.failedAttempts, tt.maxAttempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleLockout(t *testing.T) {
+	cfg := &config.LoginThrottleConfig{
+		MaxAttemptsPerUser: 3,
+		MaxAttemptsPerIP:   5,
+		LockoutDuration:    15 * time.Minute,
+		MaxLockoutDuration: time.Hour,
+		AttemptWindow:      time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		failures []struct{ username, ip string }
+		stale    bool // the earlier failures happened before the attempt window
+		username string
+		ip       string
+		wantErr  string
+	}{
+		{
+			name:     "below the threshold",
+			failures: []struct{ username, ip string }{{"john_doe", "203.0.113.10"}, {"john_doe", "203.0.113.10"}},
+			username: "john_doe",
+			ip:       "203.0.113.10",
+		},
+		{
+			name: "username locked at the threshold from any IP",
+			failures: []struct{ username, ip string }{
+				{"john_doe", "203.0.113.10"}, {"john_doe", "203.0.113.11"}, {"John_Doe", "203.0.113.12"},
+			},
+			username: "john_doe",
+			ip:       "198.51.100.1",
+			wantErr:  "account temporarily locked",
+		},
+		{
+			name: "IP locked across usernames",
+			failures: []struct{ username, ip string }{
+				{"a", "203.0.113.10"}, {"b", "203.0.113.10"}, {"c", "203.0.113.10"}, {"d", "203.0.113.10"}, {"e", "203.0.113.10"},
+			},
+			username: "john_doe",
+			ip:       "203.0.113.10",
+			wantErr:  "too many failed login attempts",
+		},
+		{
+			name: "failures outside the window are forgotten",
+			failures: []struct{ username, ip string }{
+				{"john_doe", "203.0.113.10"}, {"john_doe", "203.0.113.10"},
+			},
+			stale:    true,
+			username: "john_doe",
+			ip:       "203.0.113.10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLoginThrottleRepository{throttles: make(map[string]*model.LoginThrottle)}
+			s := NewLoginThrottleService(repo, cfg)
+
+			for _, failure := range tt.failures {
+				if err := s.RecordFailure(failure.username, failure.ip); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.stale {
+				for _, throttle := range repo.throttles {
+					throttle.LastFailedAt = throttle.LastFailedAt.Add(-2 * cfg.AttemptWindow)
+				}
+				// A single new failure must not reach the threshold together with the old ones
+				if err := s.RecordFailure(tt.username, tt.ip); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := s.CheckLogin(tt.username, tt.ip)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("CheckLogin() error = %v, want none", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("CheckLogin() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleBackoff(t *testing.T) {
+	cfg := &config.LoginThrottleConfig{
+		MaxAttemptsPerUser: 3,
+		MaxAttemptsPerIP:   100,
+		LockoutDuration:    15 * time.Minute,
+		MaxLockoutDuration: time.Hour,
+		AttemptWindow:      24 * time.Hour,
+	}
+
+	tests := []struct {
+		name        string
+		failures    int
+		wantLockout time.Duration
+	}{
+		{name: "first lockout", failures: 3, wantLockout: 15 * time.Minute},
+		{name: "doubled", failures: 4, wantLockout: 30 * time.Minute},
+		{name: "doubled again", failures: 5, wantLockout: time.Hour},
+		{name: "capped", failures: 9, wantLockout: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLoginThrottleRepository{throttles: make(map[string]*model.LoginThrottle)}
+			s := NewLoginThrottleService(repo, cfg)
+
+			start := time.Now()
+			for i := 0; i < tt.failures; i++ {
+				if err := s.RecordFailure("john_doe", "203.0.113.10"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			throttle := repo.throttles[userThrottleKey("john_doe")]
+			if throttle.LockedUntil == nil {
+				t.Fatal("username is not locked")
+			}
+			got := throttle.LockedUntil.Sub(start)
+			if got < tt.wantLockout || got > tt.wantLockout+time.Second {
+				t.Errorf("locked for %v, want %v", got, tt.wantLockout)
+			}
+
+			// A successful login clears the failures of the username
+			if err := s.RecordSuccess("john_doe"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.CheckLogin("john_doe", "203.0.113.10"); err != nil {
+				t.Errorf("CheckLogin() after success error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+const oauthClientIDPrefix = "ggc_"
+
+// OAuth2 error codes of the token endpoint (RFC 6749 section 5.2)
+const (
+	OAuthErrorInvalidRequest       = "invalid_request"
+	OAuthErrorInvalidClient        = "invalid_client"
+	OAuthErrorInvalidScope         = "invalid_scope"
+	OAuthErrorUnsupportedGrantType = "unsupported_grant_type"
+)
+
+// OAuthError is returned by the token endpoint. Code is one of the RFC 6749 error codes.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Description
+}
+
+type OAuthService struct {
+	cfg          *config.OAuthConfig
+	clientRepo   repository.OAuthClientRepository
+	roleRepo     repository.RoleRepository
+	tokenService *TokenService
+}
+
+func NewOAuthService(cfg *config.OAuthConfig, clientRepo repository.OAuthClientRepository, roleRepo repository.RoleRepository, tokenService *TokenService) *OAuthService {
+	return &OAuthService{cfg: cfg, clientRepo: clientRepo, roleRepo: roleRepo, tokenService: tokenService}
+}
+
+// IssueToken handles a token request. Only the client credentials grant is supported.
+func (s *OAuthService) IssueToken(req *model.OAuthTokenRequest) (*model.OAuthTokenResponse, error) {
+	if req.GrantType == "" {
+		return nil, &OAuthError{OAuthErrorInvalidRequest, "grant_type is required"}
+	}
+	if req.GrantType != model.GrantTypeClientCredentials {
+		return nil, &OAuthError{OAuthErrorUnsupportedGrantType, "only the client_credentials grant is supported"}
+	}
+	if req.ClientID == "" || req.ClientSecret == "" {
+		return nil, &OAuthError{OAuthErrorInvalidClient, "client authentication failed"}
+	}
+
+	client, err := s.clientRepo.FindByClientID(req.ClientID)
+	if err != nil || subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(utils.HashToken(req.ClientSecret))) != 1 ||
+		client.RevokedAt != nil {
+		return nil, &OAuthError{OAuthErrorInvalidClient, "client authentication failed"}
+	}
+
+	// Without a scope parameter the client gets everything it is allowed to request
+	scopes := client.ScopeList()
+	if requested := strings.Fields(req.Scope); len(requested) > 0 {
+		for _, scope := range requested {
+			if !slices.Contains(scopes, scope) {
+				return nil, &OAuthError{OAuthErrorInvalidScope, "scope " + scope + " is not allowed for this client"}
+			}
+		}
+		scopes = requested
+	}
+
+	accessToken, expiresAt, err := config.GenerateClientToken(client, scopes, s.cfg.TokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.clientRepo.UpdateLastUsed(client.ID, time.Now()); err != nil {
+		log.Printf("Error updating last used time of oauth client %s: %v", client.ClientID, err)
+	}
+
+	return &model.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(expiresAt).Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// ListClients returns the OAuth clients of the caller's organization
+func (s *OAuthService) ListClients(ctx context.Context) ([]model.OAuthClientResponse, error) {
+	clients, err := s.clientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.OAuthClientResponse, 0, len(clients))
+	for _, client := range clients {
+		responses = append(responses, client.ToOAuthClientResponse())
+	}
+	return responses, nil
+}
+
+// CreateClient registers an OAuth client in the caller's organization.
+// The returned response holds the only copy of the client secret.
+func (s *OAuthService) CreateClient(ctx context.Context, req *model.CreateOAuthClientRequest, canManageOrganizations bool) (*model.CreateOAuthClientResponse, error) {
+	permissions, err := s.roleRepo.FindPermissionsByNames(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range req.Scopes {
+		if !slices.ContainsFunc(permissions, func(p model.Permission) bool { return p.Name == scope }) {
+			return nil, errors.New("permission not found")
+		}
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(permissions) {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &model.OAuthClient{
+		ClientID:   oauthClientIDPrefix + hex.EncodeToString(idBytes),
+		Name:       req.Name,
+		SecretHash: utils.HashToken(secret),
+		Scopes:     strings.Join(req.Scopes, " "),
+		TenantID:   model.TenantFromContext(ctx),
+		CreatedBy:  model.ActorFromContext(ctx),
+	}
+	if err := s.clientRepo.Create(client); err != nil {
+		return nil, err
+	}
+
+	return &model.CreateOAuthClientResponse{
+		OAuthClientResponse: client.ToOAuthClientResponse(),
+		ClientSecret:        secret,
+	}, nil
+}
+
+// RevokeClient revokes an OAuth client of the caller's organization together with the tokens issued to it
+func (s *OAuthService) RevokeClient(ctx context.Context, id uint) error {
+	client, err := s.clientRepo.FindByID(ctx, id)
+	if err != nil || client.RevokedAt != nil {
+		return errors.New("oauth client not found")
+	}
+
+	if err := s.clientRepo.Revoke(client.ID, time.Now()); err != nil {
+		return err
+	}
+	return s.tokenService.RevokeClientTokens(client.ClientID, s.cfg.TokenTTL)
+}
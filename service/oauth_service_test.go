@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeOAuthClientRepository keeps OAuth clients in memory
+type fakeOAuthClientRepository struct {
+	clients []*model.OAuthClient
+}
+
+func (r *fakeOAuthClientRepository) Create(client *model.OAuthClient) error {
+	client.ID = uint(len(r.clients) + 1)
+	r.clients = append(r.clients, client)
+	return nil
+}
+
+func (r *fakeOAuthClientRepository) FindAll(ctx context.Context) ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	for _, client := range r.clients {
+		if client.TenantID == model.TenantFromContext(ctx) {
+			clients = append(clients, *client)
+		}
+	}
+	return clients, nil
+}
+
+func (r *fakeOAuthClientRepository) FindByID(ctx context.Context, id uint) (*model.OAuthClient, error) {
+	for _, client := range r.clients {
+		if client.ID == id && client.TenantID == model.TenantFromContext(ctx) {
+			return client, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOAuthClientRepository) FindByClientID(clientID string) (*model.OAuthClient, error) {
+	for _, client := range r.clients {
+		if client.ClientID == clientID {
+			return client, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOAuthClientRepository) Revoke(id uint, revokedAt time.Time) error {
+	for _, client := range r.clients {
+		if client.ID == id {
+			client.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+func (r *fakeOAuthClientRepository) UpdateLastUsed(id uint, usedAt time.Time) error {
+	for _, client := range r.clients {
+		if client.ID == id {
+			client.LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+// fakeRoleRepository knows a fixed set of roles and permissions
+type fakeRoleRepository struct {
+	repository.RoleRepository
+	roles       []model.Role
+	permissions []string
+}
+
+func (r *fakeRoleRepository) FindByID(id uint) (*model.Role, error) {
+	for _, role := range r.roles {
+		if role.ID == id {
+			return &role, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRoleRepository) FindByNames(names []string) ([]model.Role, error) {
+	var roles []model.Role
+	for _, role := range r.roles {
+		if slices.Contains(names, role.Name) {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+func (r *fakeRoleRepository) FindPermissionsByNames(names []string) ([]model.Permission, error) {
+	var permissions []model.Permission
+	for i, name := range r.permissions {
+		if slices.Contains(names, name) {
+			permissions = append(permissions, model.Permission{ID: uint(i + 1), Name: name})
+		}
+	}
+	return permissions, nil
+}
+
+func TestIssueTokenClientCredentials(t *testing.T) {
+	t.Setenv("JWT_PRIVATE_KEYS", "")
+	t.Setenv("JWT_PUBLIC_KEYS", "")
+	t.Setenv("JWT_SIGNING_KEY_ID", "")
+	t.Setenv("JWT_SECRET", "test-secret")
+	if err := config.InitJWT(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// request builds the token request from the client_id and secret of a client allowed topics:read and topics:write
+		request    func(clientID, secret string) *model.OAuthTokenRequest
+		revoked    bool
+		wantScopes []string
+		wantErr    string // OAuth error code
+	}{
+		{
+			name: "all allowed scopes by default",
+			request: func(clientID, secret string) *model.OAuthTokenRequest {
+				return &model.OAuthTokenRequest{GrantType: "client_credentials", ClientID: clientID, ClientSecret: secret}
+			},
+			wantScopes: []string{"topics:read", "topics:write"},
+		},
+		{
+			name: "narrower scope",
+			request: func(clientID, secret string) *model.OAuthTokenRequest {
+				return &model.OAuthTokenRequest{GrantType: "client_credentials", ClientID: clientID, ClientSecret: secret, Scope: "topics:read"}
+			},
+			wantScopes: []string{"topics:read"},
+		},
+		{
+			name: "scope the client is not allowed",
+			request: func(clientID, secret string) *model.OAuthTokenRequest {
+				return &model.OAuthTokenRequest{GrantType: "client_credentials", ClientID: clientID, ClientSecret: secret, Scope: "topics:read users:manage"}
+			},
+			wantErr: OAuthErrorInvalidScope,
+		},
+		{
+			name: "wrong secret",
+			request: func(clientID, secret string) *model.OAuthTokenRequest {
+				return &model.OAuthTokenRequest{GrantType: "client_credentials", ClientID: clientID, ClientSecret: secret + "x"}
+			},
+			wantErr: OAuthErrorInvalidClient,
+		},
+		{
+			name: "unknown client",
+			request: func(clientID, secret string) *model.OAuthTokenRequest {
+				return &model.OAuthTokenRequest{GrantType: "client_credentials", ClientID: "ggc_0000000000000000", ClientSecret: secret}
+			},
+			wantErr: OAuthErrorInvalidClient,
+		},
+		{
+			name: "revoked client",
+			request: func(clientID, secret string) *model.OAuthTokenRequest {
+				return &model.OAuthTokenRequest{GrantType: "client_credentials", ClientID: clientID, ClientSecret: secret}
+			},
+			revoked: true,
+			wantErr: OAuthErrorInvalidClient,
+		},
+		{
+			name: "password grant",
+			request: func(clientID, secret string) *model.OAuthTokenRequest {
+				return &model.OAuthTokenRequest{GrantType: "password", ClientID: clientID, ClientSecret: secret}
+			},
+			wantErr: OAuthErrorUnsupportedGrantType,
+		},
+		{
+			name: "missing grant type",
+			request: func(clientID, secret string) *model.OAuthTokenRequest {
+				return &model.OAuthTokenRequest{ClientID: clientID, ClientSecret: secret}
+			},
+			wantErr: OAuthErrorInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			revocationRepo := repository.NewMemoryTokenRevocationRepository()
+			tokenService := NewTokenService(repository.NewUserRepository(db), &fakeRefreshTokenRepository{}, revocationRepo, &fakeSessionRepository{}, NewSecurityEventService(&fakeSecurityEventRepository{}))
+			clientRepo := &fakeOAuthClientRepository{}
+			roleRepo := &fakeRoleRepository{permissions: []string{"topics:read", "topics:write", "users:manage"}}
+			s := NewOAuthService(&config.OAuthConfig{TokenTTL: time.Hour}, clientRepo, roleRepo, tokenService)
+
+			ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: 1, Username: "admin", TenantID: 1})
+			created, err := s.CreateClient(ctx, &model.CreateOAuthClientRequest{Name: "pharmacy-sync", Scopes: []string{"topics:read", "topics:write"}}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.revoked {
+				if err := s.RevokeClient(ctx, created.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			resp, err := s.IssueToken(tt.request(created.ClientID, created.ClientSecret))
+			if tt.wantErr != "" {
+				var oauthErr *OAuthError
+				if !errors.As(err, &oauthErr) || oauthErr.Code != tt.wantErr {
+					t.Fatalf("IssueToken() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := tokenService.ValidateAccessToken(resp.AccessToken)
+			if err != nil {
+				t.Fatalf("ValidateAccessToken() error = %v", err)
+			}
+			if claims.ClientID != created.ClientID || claims.UserID != 0 || claims.TenantID != 1 || !slices.Equal(claims.Permissions, tt.wantScopes) {
+				t.Errorf("claims = %+v, want client %s with permissions %v", claims, created.ClientID, tt.wantScopes)
+			}
+			if clientRepo.clients[0].LastUsedAt == nil {
+				t.Error("last used time not recorded")
+			}
+
+			// Revoking the client revokes the tokens already issued to it
+			if err := s.RevokeClient(ctx, created.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tokenService.ValidateAccessToken(resp.AccessToken); err == nil {
+				t.Error("token still valid after its client was revoked")
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/oidc"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+const oidcLoginTTL = 10 * time.Minute
+
+// usernameDisallowedChars matches everything we strip from IdP usernames
+var usernameDisallowedChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+type OIDCService struct {
+	cfg              *config.OIDCConfig
+	provider         *oidc.Provider
+	authRequestRepo  repository.OIDCAuthRequestRepository
+	identityRepo     repository.UserIdentityRepository
+	userRepo         *repository.UserRepository
+	roleRepo         repository.RoleRepository
+	orgRepo          repository.OrganizationRepository
+	tokenService     *TokenService
+	twoFactorService *TwoFactorService
+	securityEvents   *SecurityEventService
+}
+
+func NewOIDCService(cfg *config.OIDCConfig, provider *oidc.Provider, authRequestRepo repository.OIDCAuthRequestRepository, identityRepo repository.UserIdentityRepository, userRepo *repository.UserRepository, roleRepo repository.RoleRepository, orgRepo repository.OrganizationRepository, tokenService *TokenService, twoFactorService *TwoFactorService, securityEvents *SecurityEventService) *OIDCService {
+	return &OIDCService{
+		cfg:              cfg,
+		provider:         provider,
+		authRequestRepo:  authRequestRepo,
+		identityRepo:     identityRepo,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		orgRepo:          orgRepo,
+		tokenService:     tokenService,
+		twoFactorService: twoFactorService,
+		securityEvents:   securityEvents,
+	}
+}
+
+// Enabled reports whether an identity provider is configured
+func (s *OIDCService) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// StartLogin records a pending login and returns the provider URL to redirect to together with the state,
+// which the caller binds to the browser so the callback cannot be replayed from elsewhere
+func (s *OIDCService) StartLogin(ctx context.Context) (string, string, error) {
+	if !s.cfg.Enabled {
+		return "", "", errors.New("single sign-on is not configured")
+	}
+
+	now := time.Now()
+	if err := s.authRequestRepo.DeleteExpired(now); err != nil {
+		log.Printf("Error deleting expired OIDC logins: %v", err)
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := utils.GenerateRandomToken(48)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.authRequestRepo.Create(&model.OIDCAuthRequest{
+		StateHash:    utils.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(oidcLoginTTL),
+	}); err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// CompleteLogin exchanges the authorization code, maps the ID token onto a local user and issues our tokens
+func (s *OIDCService) CompleteLogin(ctx context.Context, state, browserState, code string, client model.ClientInfo) (*model.LoginResponse, error) {
+	if !s.cfg.Enabled {
+		return nil, errors.New("single sign-on is not configured")
+	}
+	if state == "" || code == "" || state != browserState {
+		return nil, errors.New("invalid or expired login state")
+	}
+
+	authRequest, err := s.authRequestRepo.FindByStateHash(utils.HashToken(state))
+	if err != nil || time.Now().After(authRequest.ExpiresAt) {
+		return nil, errors.New("invalid or expired login state")
+	}
+	consumed, err := s.authRequestRepo.Consume(authRequest.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, errors.New("invalid or expired login state")
+	}
+
+	tokens, err := s.provider.Exchange(ctx, code, authRequest.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		s.securityEvents.Record(model.SecurityEventLoginOIDC, nil, "", client, model.SecurityReasonSSOFailed)
+		return nil, errors.New("single sign-on failed")
+	}
+
+	claims, err := s.provider.VerifyIDToken(ctx, tokens.IDToken, authRequest.Nonce)
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		s.securityEvents.Record(model.SecurityEventLoginOIDC, nil, "", client, model.SecurityReasonSSOFailed)
+		return nil, errors.New("single sign-on failed")
+	}
+
+	user, err := s.resolveUser(claims)
+	if err != nil {
+		s.securityEvents.Record(model.SecurityEventLoginOIDC, nil, claims.Email, client, model.SecurityReasonUnknownUser)
+		return nil, err
+	}
+
+	if user.IsServiceAccount {
+		s.securityEvents.Record(model.SecurityEventLoginOIDC, user, user.Username, client, model.SecurityReasonServiceAccount)
+		return nil, errors.New("no local account for this identity")
+	}
+	if !user.IsActive {
+		s.securityEvents.Record(model.SecurityEventLoginOIDC, user, user.Username, client, model.SecurityReasonInactiveAccount)
+		return nil, inactiveAccountError(user)
+	}
+
+	// The identity provider stands in for the password only, a code still has to be verified
+	if user.TwoFactorEnabled {
+		s.securityEvents.Record(model.SecurityEventLoginOIDC, user, user.Username, client, model.SecurityReasonTwoFactorPending)
+		return s.twoFactorService.IssueChallenge(user)
+	}
+
+	s.securityEvents.Record(model.SecurityEventLoginOIDC, user, user.Username, client, "")
+	return s.tokenService.IssueTokens(user, client)
+}
+
+// resolveUser finds the local user of an identity. Unknown identities are linked to an existing user
+// with the same verified email or provisioned as a new user, depending on the configuration.
+func (s *OIDCService) resolveUser(claims *oidc.IDTokenClaims) (*model.User, error) {
+	issuer := s.provider.Issuer()
+	now := time.Now()
+
+	identity, err := s.identityRepo.FindByIssuerAndSubject(issuer, claims.Subject)
+	if err == nil {
+		if err := s.identityRepo.UpdateLastLogin(identity.ID, claims.Email, now); err != nil {
+			return nil, err
+		}
+		user, err := s.userRepo.GetUserByID(identity.UserID)
+		if err != nil {
+			return nil, errors.New("no local account for this identity")
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var user *model.User
+	if s.cfg.LinkByEmail && claims.Email != "" && claims.EmailVerified {
+		if existing, err := s.userRepo.GetUserByEmail(claims.Email); err == nil {
+			if !s.canLinkByEmail(existing) {
+				log.Printf("Refused to link identity %s at %s to user %s by email", claims.Subject, issuer, existing.Username)
+				return nil, errors.New("no local account for this identity")
+			}
+			user = existing
+		}
+	}
+
+	if user == nil {
+		if !s.cfg.AutoProvision {
+			return nil, errors.New("no local account for this identity")
+		}
+		user, err = s.provisionUser(claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.identityRepo.Create(&model.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      issuer,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// canLinkByEmail reports whether an identity may be linked to an existing user by email alone.
+// Only regular users of the organization SSO users join qualify; linking a privileged account would
+// hand it to whoever controls that address at the identity provider.
+func (s *OIDCService) canLinkByEmail(user *model.User) bool {
+	if user.IsServiceAccount {
+		return false
+	}
+	if slices.ContainsFunc(user.RoleNames(), func(r string) bool { return r == model.RoleAdmin || r == model.RolePlatformAdmin }) {
+		return false
+	}
+	permissions := user.EffectivePermissions()
+	for _, privileged := range []string{model.PermissionUsersManage, model.PermissionAPIKeysManage, model.PermissionOrganizationsManage} {
+		if slices.Contains(permissions, privileged) {
+			return false
+		}
+	}
+
+	organization, err := s.orgRepo.FindBySlug(s.cfg.Organization)
+	return err == nil && user.TenantID == organization.ID
+}
+
+// provisionUser creates a local user for an identity. The user gets an unusable random password
+// and can only sign in through the identity provider until a password is reset.
+func (s *OIDCService) provisionUser(claims *oidc.IDTokenClaims) (*model.User, error) {
+	if claims.Email == "" {
+		return nil, errors.New("identity provider did not return an email address")
+	}
+	if s.userRepo.CheckEmailExists(claims.Email, 0) {
+		return nil, errors.New("email already exists")
+	}
+
+	username, err := s.availableUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := config.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.FindByNames([]string{s.cfg.DefaultRole})
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, errors.New("role not found")
+	}
+
+	organization, err := s.orgRepo.FindBySlug(s.cfg.Organization)
+	if err != nil {
+		return nil, errors.New("organization not found")
+	}
+
+	fullName := claims.Name
+	if fullName == "" {
+		fullName = username
+	}
+
+	user := &model.User{
+		Username: username,
+		Email:    claims.Email,
+		Password: hashedPassword,
+		FullName: fullName,
+		Roles:    roles,
+		TenantID: organization.ID,
+		IsActive: true,
+	}
+	if err := s.userRepo.CreateUser(user); err != nil {
+		return nil, handleDuplicateUserError(err)
+	}
+
+	log.Printf("Provisioned user %s for identity %s at %s", user.Username, claims.Subject, s.provider.Issuer())
+	return user, nil
+}
+
+// availableUsername derives a username from the preferred username or email and adds a suffix when it is taken
+func (s *OIDCService) availableUsername(claims *oidc.IDTokenClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameDisallowedChars.ReplaceAllString(base, "")
+	if len(base) > 90 {
+		base = base[:90]
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 2; i <= 100; i++ {
+		if !s.userRepo.CheckUsernameExists(candidate) {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", errors.New("username already exists")
+}
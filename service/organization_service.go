@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+)
+
+// organizationSlugPattern allows lowercase words separated by single dashes, e.g. "sukhumvit-clinic"
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type OrganizationService struct {
+	organizationRepo repository.OrganizationRepository
+}
+
+func NewOrganizationService(organizationRepo repository.OrganizationRepository) *OrganizationService {
+	return &OrganizationService{organizationRepo: organizationRepo}
+}
+
+// ListOrganizations returns all organizations
+func (s *OrganizationService) ListOrganizations() ([]model.Organization, error) {
+	return s.organizationRepo.FindAll()
+}
+
+// GetOrganization returns a single organization
+func (s *OrganizationService) GetOrganization(id uint) (*model.Organization, error) {
+	organization, err := s.organizationRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("organization not found")
+	}
+	return organization, nil
+}
+
+// CreateOrganization creates a new, empty organization
+func (s *OrganizationService) CreateOrganization(req *model.CreateOrganizationRequest) (*model.Organization, error) {
+	if !organizationSlugPattern.MatchString(req.Slug) {
+		return nil, errors.New("invalid organization slug")
+	}
+	if _, err := s.organizationRepo.FindByName(req.Name); err == nil {
+		return nil, errors.New("organization name already exists")
+	}
+	if _, err := s.organizationRepo.FindBySlug(req.Slug); err == nil {
+		return nil, errors.New("organization slug already exists")
+	}
+
+	organization := &model.Organization{Name: req.Name, Slug: req.Slug}
+	if err := s.organizationRepo.Create(organization); err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
+
+// UpdateOrganization renames an organization. The slug is fixed because configuration refers to it.
+func (s *OrganizationService) UpdateOrganization(id uint, req *model.UpdateOrganizationRequest) (*model.Organization, error) {
+	organization, err := s.organizationRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("organization not found")
+	}
+
+	if req.Name != nil {
+		if existing, err := s.organizationRepo.FindByName(*req.Name); err == nil && existing.ID != organization.ID {
+			return nil, errors.New("organization name already exists")
+		}
+		organization.Name = *req.Name
+	}
+
+	if err := s.organizationRepo.Update(organization); err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/mailer"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+const passwordResetTokenTTL = time.Hour
+
+type PasswordResetService struct {
+	userRepo       *repository.UserRepository
+	resetRepo      repository.PasswordResetRepository
+	tokenService   *TokenService
+	securityEvents *SecurityEventService
+	mailer         mailer.Mailer
+	linkBaseURL    string
+}
+
+func NewPasswordResetService(userRepo *repository.UserRepository, resetRepo repository.PasswordResetRepository, tokenService *TokenService, securityEvents *SecurityEventService, m mailer.Mailer, linkBaseURL string) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:       userRepo,
+		resetRepo:      resetRepo,
+		tokenService:   tokenService,
+		securityEvents: securityEvents,
+		mailer:         m,
+		linkBaseURL:    linkBaseURL,
+	}
+}
+
+// RequestPasswordReset mails a one-time reset link to the user owning the email address.
+// It never reports whether the address exists so it cannot be used to enumerate accounts.
+func (s *PasswordResetService) RequestPasswordReset(req *model.ForgotPasswordRequest) error {
+	user, err := s.userRepo.GetUserByEmail(req.Email)
+	if err != nil || !user.IsActive {
+		return nil
+	}
+
+	// Only the most recently mailed link stays valid
+	now := time.Now()
+	if err := s.resetRepo.InvalidateByUserID(user.ID, now); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := s.resetRepo.Create(&model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(passwordResetTokenTTL),
+	}); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.linkBaseURL, url.QueryEscape(token))
+	if err := s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nUse the link below to set a new password. The link expires in %s and can only be used once.\n\n%s\n\nIf you did not request a password reset you can ignore this mail.\n",
+			user.FullName, passwordResetTokenTTL, link),
+	}); err != nil {
+		log.Printf("Error sending password reset mail to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and revokes all sessions of the user
+func (s *PasswordResetService) ResetPassword(req *model.ResetPasswordRequest, client model.ClientInfo) error {
+	resetToken, err := s.resetRepo.FindByTokenHash(utils.HashToken(req.Token))
+	if err != nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		s.securityEvents.Record(model.SecurityEventPasswordReset, nil, "", client, model.SecurityReasonInvalidToken)
+		return errors.New("invalid or expired reset token")
+	}
+
+	user, err := s.userRepo.GetUserByID(resetToken.UserID)
+	if err != nil || !user.IsActive {
+		s.securityEvents.Record(model.SecurityEventPasswordReset, nil, "", client, model.SecurityReasonInactiveAccount)
+		return errors.New("invalid or expired reset token")
+	}
+
+	// The token stays usable until a valid password has been given, so a rejected one can be corrected
+	if err := config.ValidatePassword(req.NewPassword, user.Username); err != nil {
+		return err
+	}
+
+	hashedPassword, err := config.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	marked, err := s.resetRepo.MarkUsed(resetToken.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !marked {
+		s.securityEvents.Record(model.SecurityEventPasswordReset, nil, "", client, model.SecurityReasonInvalidToken)
+		return errors.New("invalid or expired reset token")
+	}
+
+	user.Password = hashedPassword
+	user.MustChangePassword = false
+	if err := s.userRepo.UpdateUser(user, "password", "must_change_password"); err != nil {
+		return err
+	}
+
+	if err := s.tokenService.RevokeAllUserSessions(user.ID); err != nil {
+		return err
+	}
+
+	s.securityEvents.Record(model.SecurityEventPasswordReset, user, user.Username, client, "")
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/mailer"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakePasswordResetRepository keeps reset tokens in memory. MarkUsed only succeeds once per token, like
+// the conditional update of the database repository.
+type fakePasswordResetRepository struct {
+	tokens []*model.PasswordResetToken
+}
+
+func (r *fakePasswordResetRepository) Create(token *model.PasswordResetToken) error {
+	token.ID = uint(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakePasswordResetRepository) FindByTokenHash(tokenHash string) (*model.PasswordResetToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePasswordResetRepository) MarkUsed(id uint, usedAt time.Time) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			token.UsedAt = &usedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakePasswordResetRepository) InvalidateByUserID(userID uint, usedAt time.Time) error {
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+// fakeMailer keeps the mails it was asked to send
+type fakeMailer struct {
+	sent []mailer.Message
+}
+
+func (m *fakeMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// tokenFromMail returns the token of the link in a mail
+func tokenFromMail(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+	start := strings.Index(msg.Body, "http")
+	if start < 0 {
+		t.Fatalf("no link in mail %q", msg.Body)
+	}
+	link, err := url.Parse(strings.Fields(msg.Body[start:])[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return link.Query().Get("token")
+}
+
+func TestResetPasswordTokenUse(t *testing.T) {
+	tests := []struct {
+		name string
+		// use runs the reset with the token of the first mail; the first error is returned
+		use     func(s *PasswordResetService, resetRepo *fakePasswordResetRepository, token string) error
+		wantErr string
+	}{
+		{
+			name: "fresh token",
+			use: func(s *PasswordResetService, _ *fakePasswordResetRepository, token string) error {
+				return s.ResetPassword(&model.ResetPasswordRequest{Token: token, NewPassword: "n3w-Passw0rd"}, model.ClientInfo{})
+			},
+		},
+		{
+			name: "token used a second time",
+			use: func(s *PasswordResetService, _ *fakePasswordResetRepository, token string) error {
+				if err := s.ResetPassword(&model.ResetPasswordRequest{Token: token, NewPassword: "n3w-Passw0rd"}, model.ClientInfo{}); err != nil {
+					return err
+				}
+				return s.ResetPassword(&model.ResetPasswordRequest{Token: token, NewPassword: "an0ther-Passw0rd"}, model.ClientInfo{})
+			},
+			wantErr: "invalid or expired reset token",
+		},
+		{
+			name: "token superseded by a newer mail",
+			use: func(s *PasswordResetService, _ *fakePasswordResetRepository, token string) error {
+				if err := s.RequestPasswordReset(&model.ForgotPasswordRequest{Email: "somchai@example.com"}); err != nil {
+					return err
+				}
+				return s.ResetPassword(&model.ResetPasswordRequest{Token: token, NewPassword: "n3w-Passw0rd"}, model.ClientInfo{})
+			},
+			wantErr: "invalid or expired reset token",
+		},
+		{
+			name: "expired token",
+			use: func(s *PasswordResetService, resetRepo *fakePasswordResetRepository, token string) error {
+				resetRepo.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+				return s.ResetPassword(&model.ResetPasswordRequest{Token: token, NewPassword: "n3w-Passw0rd"}, model.ClientInfo{})
+			},
+			wantErr: "invalid or expired reset token",
+		},
+		{
+			name: "unknown token",
+			use: func(s *PasswordResetService, _ *fakePasswordResetRepository, token string) error {
+				return s.ResetPassword(&model.ResetPasswordRequest{Token: token + "x", NewPassword: "n3w-Passw0rd"}, model.ClientInfo{})
+			},
+			wantErr: "invalid or expired reset token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			onQuery(t, db, func(tx *gorm.DB) {
+				if user, ok := tx.Statement.Dest.(*model.User); ok {
+					*user = model.User{ID: 2, Username: "somchai", Email: "somchai@example.com", IsActive: true}
+				}
+			})
+
+			userRepo := repository.NewUserRepository(db)
+			resetRepo := &fakePasswordResetRepository{}
+			mail := &fakeMailer{}
+			securityEvents := NewSecurityEventService(&fakeSecurityEventRepository{})
+			tokenService := NewTokenService(userRepo, &fakeRefreshTokenRepository{}, repository.NewMemoryTokenRevocationRepository(), &fakeSessionRepository{}, securityEvents)
+			s := NewPasswordResetService(userRepo, resetRepo, tokenService, securityEvents, mail, "http://localhost:3000")
+
+			if err := s.RequestPasswordReset(&model.ForgotPasswordRequest{Email: "somchai@example.com"}); err != nil {
+				t.Fatal(err)
+			}
+			if len(mail.sent) != 1 {
+				t.Fatalf("%d mails sent, want 1", len(mail.sent))
+			}
+
+			err := tt.use(s, resetRepo, tokenFromMail(t, mail.sent[0]))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ResetPassword() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("ResetPassword() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+// PrivacyService exports and erases the personal data of a user (PDPA data subject requests)
+type PrivacyService struct {
+	userRepo         *repository.UserRepository
+	personalDataRepo repository.PersonalDataRepository
+	tokenService     *TokenService
+	throttleService  *LoginThrottleService
+}
+
+func NewPrivacyService(userRepo *repository.UserRepository, personalDataRepo repository.PersonalDataRepository, tokenService *TokenService, throttleService *LoginThrottleService) *PrivacyService {
+	return &PrivacyService{
+		userRepo:         userRepo,
+		personalDataRepo: personalDataRepo,
+		tokenService:     tokenService,
+		throttleService:  throttleService,
+	}
+}
+
+// ExportPersonalData bundles everything stored about a user into a zip archive of JSON files.
+// It returns the archive together with a suggested file name.
+func (s *PrivacyService) ExportPersonalData(userID uint) ([]byte, string, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, "", errors.New("user not found")
+	}
+
+	topics, err := s.personalDataRepo.FindTopicsByActor(user.TenantID, user.Username)
+	if err != nil {
+		return nil, "", err
+	}
+	details, err := s.personalDataRepo.FindTopicDetailsByActor(user.TenantID, user.Username)
+	if err != nil {
+		return nil, "", err
+	}
+	sessions, err := s.personalDataRepo.FindSessions(user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	identities, err := s.personalDataRepo.FindIdentities(user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	apiKeys, err := s.personalDataRepo.FindAPIKeys(user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	securityEvents, err := s.personalDataRepo.FindSecurityEvents(user.ID, user.Username)
+	if err != nil {
+		return nil, "", err
+	}
+	impersonations, err := s.personalDataRepo.FindImpersonations(user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", user},
+		{"topics.json", topics},
+		{"topic_details.json", details},
+		{"sessions.json", sessions},
+		{"login_history.json", securityEvents},
+		{"sso_identities.json", identities},
+		{"api_keys.json", apiKeys},
+		{"impersonations.json", impersonations},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, "", err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, "", err
+	}
+
+	filename := fmt.Sprintf("personal-data-%s-%s.zip", user.Username, time.Now().Format("20060102"))
+	return buf.Bytes(), filename, nil
+}
+
+// EraseOwnAccount erases the account of the authenticated user after checking their password
+func (s *PrivacyService) EraseOwnAccount(userID uint, req *model.EraseAccountRequest) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !config.CheckPassword(req.Password, user.Password) {
+		return errors.New("current password is incorrect")
+	}
+	return s.erase(user)
+}
+
+// EraseUser erases the account of another user on behalf of an admin
+func (s *PrivacyService) EraseUser(id uint, actingUserID uint) error {
+	if id == actingUserID {
+		return errors.New("cannot erase your own account here")
+	}
+
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	return s.erase(user)
+}
+
+// erase signs the user out everywhere and replaces their personal data with placeholders.
+// The record itself stays (soft-deleted) so that topics and details keep a consistent author.
+func (s *PrivacyService) erase(user *model.User) error {
+	if err := s.tokenService.RevokeAllUserSessions(user.ID); err != nil {
+		return err
+	}
+
+	// The old password must not keep working in case the record is ever restored
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := config.HashPassword(randomPassword)
+	if err != nil {
+		return err
+	}
+
+	previousUsername := user.Username
+	user.Username = fmt.Sprintf("deleted-user-%d", user.ID)
+	user.Email = fmt.Sprintf("deleted-user-%d@erased.invalid", user.ID)
+	user.FullName = model.ErasedUserFullName
+	user.Password = hashedPassword
+	user.IsActive = false
+	user.MustChangePassword = false
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = ""
+	user.RegistrationStatus = ""
+	user.EmailVerifiedAt = nil
+	user.PendingEmail = ""
+	user.Preferences = model.UserPreferences{}
+
+	if err := s.personalDataRepo.Anonymize(user, previousUsername); err != nil {
+		return err
+	}
+
+	if err := s.throttleService.Unlock(previousUsername); err != nil {
+		log.Printf("Error clearing login throttle of erased user %d: %v", user.ID, err)
+	}
+
+	log.Printf("Personal data of user %d has been erased", user.ID)
+	return nil
+}
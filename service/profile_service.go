@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+)
+
+type ProfileService struct {
+	userRepo            *repository.UserRepository
+	registrationService *RegistrationService
+}
+
+func NewProfileService(userRepo *repository.UserRepository, registrationService *RegistrationService) *ProfileService {
+	return &ProfileService{userRepo: userRepo, registrationService: registrationService}
+}
+
+// GetProfile returns the current state of the user from the database
+func (s *ProfileService) GetProfile(userID uint) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	response := user.ToUserResponse()
+	return &response, nil
+}
+
+// UpdateProfile updates the full name and preferences of the user. A new email address is
+// recorded as pending and only replaces the current one once the mailed link has been opened.
+func (s *ProfileService) UpdateProfile(userID uint, req *model.UpdateProfileRequest) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	var columns []string
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+		columns = append(columns, "full_name")
+	}
+
+	if prefs := req.Preferences; prefs != nil {
+		if prefs.Language != nil {
+			user.Preferences.Language = *prefs.Language
+		}
+		if prefs.Timezone != nil {
+			user.Preferences.Timezone = *prefs.Timezone
+		}
+		if prefs.Theme != nil {
+			user.Preferences.Theme = *prefs.Theme
+		}
+		columns = append(columns, "preferences")
+	}
+
+	// Asking for the current address again withdraws a pending change
+	changeEmail := req.Email != nil && *req.Email != user.Email && *req.Email != user.PendingEmail
+	if req.Email != nil && *req.Email == user.Email {
+		user.PendingEmail = ""
+		columns = append(columns, "pending_email")
+	}
+
+	if err := s.userRepo.UpdateUser(user, columns...); err != nil {
+		return nil, err
+	}
+
+	if changeEmail {
+		if err := s.registrationService.RequestEmailChange(user, *req.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	response := user.ToUserResponse()
+	return &response, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/mailer"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+type RegistrationService struct {
+	cfg              *config.RegistrationConfig
+	userRepo         *repository.UserRepository
+	roleRepo         repository.RoleRepository
+	organizationRepo repository.OrganizationRepository
+	verificationRepo repository.EmailVerificationRepository
+	mailer           mailer.Mailer
+	linkBaseURL      string
+}
+
+func NewRegistrationService(cfg *config.RegistrationConfig, userRepo *repository.UserRepository, roleRepo repository.RoleRepository, organizationRepo repository.OrganizationRepository, verificationRepo repository.EmailVerificationRepository, m mailer.Mailer, linkBaseURL string) *RegistrationService {
+	return &RegistrationService{
+		cfg:              cfg,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		organizationRepo: organizationRepo,
+		verificationRepo: verificationRepo,
+		mailer:           m,
+		linkBaseURL:      linkBaseURL,
+	}
+}
+
+// Register creates an account for a new user. Depending on the registration mode the user
+// can log in right away, after confirming the email address, or after an admin approved the account.
+func (s *RegistrationService) Register(req *model.RegisterRequest) (*model.RegisterResponse, error) {
+	if s.cfg.Mode == config.RegistrationDisabled {
+		return nil, errors.New("registration is disabled")
+	}
+
+	if s.userRepo.CheckUsernameExists(req.Username) {
+		return nil, errors.New("username already exists")
+	}
+	if s.userRepo.CheckEmailExists(req.Email, 0) {
+		return nil, errors.New("email already exists")
+	}
+
+	if err := config.ValidatePassword(req.Password, req.Username); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := config.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.FindByNames([]string{s.cfg.DefaultRole})
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, errors.New("role not found")
+	}
+
+	organization, err := s.organizationRepo.FindBySlug(s.cfg.Organization)
+	if err != nil {
+		return nil, errors.New("organization not found")
+	}
+
+	user := &model.User{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: hashedPassword,
+		FullName: req.FullName,
+		Roles:    roles,
+		TenantID: organization.ID,
+	}
+
+	message := "Registration complete, you can log in now"
+	switch s.cfg.Mode {
+	case config.RegistrationEmailVerification:
+		user.RegistrationStatus = model.RegistrationPendingVerification
+		message = "Check your inbox to verify your email address"
+	case config.RegistrationAdminApproval:
+		user.RegistrationStatus = model.RegistrationPendingApproval
+		message = "Your account will be usable once an administrator approves it"
+	default:
+		user.IsActive = true
+	}
+
+	if err := s.userRepo.CreateUser(user); err != nil {
+		return nil, handleDuplicateUserError(err)
+	}
+
+	if user.RegistrationStatus == model.RegistrationPendingVerification {
+		if err := s.sendVerification(user, user.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("User %s registered (%s)", user.Username, s.cfg.Mode)
+	return &model.RegisterResponse{User: user.ToUserResponse(), Message: message}, nil
+}
+
+// VerifyEmail confirms an email address with a one-time token. A user waiting for verification becomes active.
+func (s *RegistrationService) VerifyEmail(req *model.VerifyEmailRequest) (*model.UserResponse, error) {
+	verification, err := s.verificationRepo.FindByTokenHash(utils.HashToken(req.Token))
+	if err != nil || verification.UsedAt != nil || time.Now().After(verification.ExpiresAt) {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	now := time.Now()
+	marked, err := s.verificationRepo.MarkUsed(verification.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	// A link mailed to an address the user no longer has, or no longer wants, proves nothing
+	user, err := s.userRepo.GetUserByID(verification.UserID)
+	if err != nil || (user.Email != verification.Email && user.PendingEmail != verification.Email) {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	// Confirming a requested change replaces the current address
+	columns := []string{"email_verified_at"}
+	if verification.Email == user.PendingEmail {
+		if s.userRepo.CheckEmailExists(user.PendingEmail, user.ID) {
+			return nil, errors.New("email already exists")
+		}
+		user.Email = user.PendingEmail
+		user.PendingEmail = ""
+		columns = append(columns, "email", "pending_email")
+	}
+
+	user.EmailVerifiedAt = &now
+	if user.RegistrationStatus == model.RegistrationPendingVerification {
+		user.RegistrationStatus = ""
+		user.IsActive = true
+		columns = append(columns, "registration_status", "is_active")
+	}
+	if err := s.userRepo.UpdateUser(user, columns...); err != nil {
+		return nil, handleDuplicateUserError(err)
+	}
+
+	response := user.ToUserResponse()
+	return &response, nil
+}
+
+// RequestEmailChange records a new address for the user and mails a verification link to it.
+// The current address stays in use until the link is opened.
+func (s *RegistrationService) RequestEmailChange(user *model.User, email string) error {
+	if s.userRepo.CheckEmailExists(email, user.ID) {
+		return errors.New("email already exists")
+	}
+
+	user.PendingEmail = email
+	if err := s.userRepo.UpdateUser(user, "pending_email"); err != nil {
+		return err
+	}
+	return s.sendVerification(user, email)
+}
+
+// ResendVerification mails a new verification link to a user who has not verified the address yet.
+// It never reports whether the address exists so it cannot be used to enumerate accounts.
+func (s *RegistrationService) ResendVerification(req *model.ResendVerificationRequest) error {
+	user, err := s.userRepo.GetUserByEmail(req.Email)
+	if err != nil || user.RegistrationStatus != model.RegistrationPendingVerification {
+		return nil
+	}
+	return s.sendVerification(user, user.Email)
+}
+
+// ApproveUser activates a self-registered user waiting for approval and lets them know by mail
+func (s *RegistrationService) ApproveUser(id uint) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.RegistrationStatus != model.RegistrationPendingApproval {
+		return nil, errors.New("user is not awaiting approval")
+	}
+
+	user.RegistrationStatus = ""
+	user.IsActive = true
+	if err := s.userRepo.UpdateUser(user, "registration_status", "is_active"); err != nil {
+		return nil, err
+	}
+
+	if err := s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been approved",
+		Body: fmt.Sprintf("Hello %s,\n\nYour account %s has been approved. You can log in at %s\n",
+			user.FullName, user.Username, s.linkBaseURL),
+	}); err != nil {
+		log.Printf("Error sending approval mail to user %d: %v", user.ID, err)
+	}
+
+	response := user.ToUserResponse()
+	return &response, nil
+}
+
+// sendVerification mails a one-time link confirming an email address of the user, the current
+// or a requested new one. Only the most recently mailed link stays valid.
+func (s *RegistrationService) sendVerification(user *model.User, email string) error {
+	now := time.Now()
+	if err := s.verificationRepo.InvalidateByUserID(user.ID, now); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verificationRepo.Create(&model.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(s.cfg.VerificationTTL),
+	}); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.linkBaseURL, url.QueryEscape(token))
+	if err := s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nUse the link below to confirm your email address. The link expires in %s and can only be used once.\n\n%s\n\nIf you did not create an account or change your address you can ignore this mail.\n",
+			user.FullName, s.cfg.VerificationTTL, link),
+	}); err != nil {
+		log.Printf("Error sending verification mail to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
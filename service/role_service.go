@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"slices"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+)
+
+type RoleService struct {
+	roleRepo     repository.RoleRepository
+	tokenService *TokenService
+}
+
+func NewRoleService(roleRepo repository.RoleRepository, tokenService *TokenService) *RoleService {
+	return &RoleService{roleRepo: roleRepo, tokenService: tokenService}
+}
+
+// ListRoles returns all roles with their permissions
+func (s *RoleService) ListRoles() ([]model.Role, error) {
+	return s.roleRepo.FindAll()
+}
+
+// ListPermissions returns all known permissions
+func (s *RoleService) ListPermissions() ([]model.Permission, error) {
+	return s.roleRepo.FindAllPermissions()
+}
+
+// GetRole returns a single role
+func (s *RoleService) GetRole(id uint) (*model.Role, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("role not found")
+	}
+	return role, nil
+}
+
+// CreateRole creates a role granting the given permissions.
+// Only callers that may manage organizations can grant organizations:manage.
+func (s *RoleService) CreateRole(req *model.CreateRoleRequest, canManageOrganizations bool) (*model.Role, error) {
+	existing, err := s.roleRepo.FindByNames([]string{req.Name})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, errors.New("role name already exists")
+	}
+
+	permissions, err := resolvePermissions(s.roleRepo, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(permissions) {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	role := &model.Role{
+		Name:             req.Name,
+		Description:      req.Description,
+		Permissions:      permissions,
+		RequireTwoFactor: req.RequireTwoFactor,
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole updates the description, the two-factor requirement and/or the permissions of a role.
+// Roles granting organizations:manage can only be changed by callers that may manage organizations.
+func (s *RoleService) UpdateRole(id uint, req *model.UpdateRoleRequest, canManageOrganizations bool) (*model.Role, error) {
+	role, err := s.GetRole(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(role.Permissions) {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	if req.Description != nil || req.RequireTwoFactor != nil {
+		if req.Description != nil {
+			role.Description = *req.Description
+		}
+		if req.RequireTwoFactor != nil {
+			role.RequireTwoFactor = *req.RequireTwoFactor
+		}
+		if err := s.roleRepo.Update(role); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Permissions != nil {
+		// The admin role always holds every permission
+		if role.Name == model.RoleAdmin {
+			return nil, errors.New("cannot change the permissions of the admin role")
+		}
+
+		permissions, err := resolvePermissions(s.roleRepo, *req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if !canManageOrganizations && grantsOrganizationsManage(permissions) {
+			return nil, errors.New("insufficient permissions")
+		}
+		if err := s.roleRepo.ReplacePermissions(role, permissions); err != nil {
+			return nil, err
+		}
+		role.Permissions = permissions
+	}
+
+	// Members hold tokens with the old permissions or two-factor requirement
+	if req.Permissions != nil || req.RequireTwoFactor != nil {
+		if err := s.invalidateMemberTokens(role.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return role, nil
+}
+
+// DeleteRole deletes a role and removes it from all users
+func (s *RoleService) DeleteRole(id uint, canManageOrganizations bool) error {
+	role, err := s.GetRole(id)
+	if err != nil {
+		return err
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(role.Permissions) {
+		return errors.New("insufficient permissions")
+	}
+
+	if role.Name == model.RoleAdmin {
+		return errors.New("cannot delete the admin role")
+	}
+
+	// Look up the members before their assignments are removed
+	userIDs, err := s.roleRepo.FindUserIDs(role.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.roleRepo.Delete(role.ID); err != nil {
+		return err
+	}
+	return s.invalidateAccessTokens(userIDs)
+}
+
+// invalidateMemberTokens invalidates the access tokens of every user the role is assigned to
+func (s *RoleService) invalidateMemberTokens(roleID uint) error {
+	userIDs, err := s.roleRepo.FindUserIDs(roleID)
+	if err != nil {
+		return err
+	}
+	return s.invalidateAccessTokens(userIDs)
+}
+
+func (s *RoleService) invalidateAccessTokens(userIDs []uint) error {
+	for _, userID := range userIDs {
+		if err := s.tokenService.InvalidateAccessTokens(userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvePermissions loads permissions by name and fails if any of them does not exist
+func resolvePermissions(roleRepo repository.RoleRepository, names []string) ([]model.Permission, error) {
+	if len(names) == 0 {
+		return []model.Permission{}, nil
+	}
+
+	permissions, err := roleRepo.FindPermissionsByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(permissions, func(p model.Permission) bool { return p.Name == name }) {
+			return nil, errors.New("permission not found")
+		}
+	}
+	return permissions, nil
+}
+
+// grantsOrganizationsManage reports whether the permissions span tenants.
+// Admins of a single organization must not hand them out, or they could reach into other organizations.
+func grantsOrganizationsManage(permissions []model.Permission) bool {
+	return slices.ContainsFunc(permissions, func(p model.Permission) bool { return p.Name == model.PermissionOrganizationsManage })
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+)
+
+// defaultSecurityEventLimit is the number of events returned when the query does not set a limit
+const defaultSecurityEventLimit = 100
+
+type SecurityEventService struct {
+	eventRepo repository.SecurityEventRepository
+}
+
+func NewSecurityEventService(eventRepo repository.SecurityEventRepository) *SecurityEventService {
+	return &SecurityEventService{eventRepo: eventRepo}
+}
+
+// Record stores a security event about user, or about the attempted username when user is nil.
+// An empty reason marks the event as successful. Storing failures are only logged, they never
+// break the action being recorded.
+func (s *SecurityEventService) Record(eventType string, user *model.User, username string, client model.ClientInfo, reason string) {
+	event := &model.SecurityEvent{
+		Type:      eventType,
+		Success:   reason == "" || reason == model.SecurityReasonTwoFactorPending,
+		Reason:    reason,
+		Username:  truncate(username, 100),
+		IPAddress: truncate(client.IPAddress, 64),
+		UserAgent: truncate(client.UserAgent, 512),
+	}
+	if user != nil {
+		event.TenantID = user.TenantID
+		event.UserID = &user.ID
+		event.Username = user.Username
+	}
+	s.store(event)
+}
+
+// RecordRoleChange stores the replacement of the roles of a user by the caller in ctx
+func (s *SecurityEventService) RecordRoleChange(ctx context.Context, user *model.User, previousRoles []string) {
+	client := model.ClientFromContext(ctx)
+	s.store(&model.SecurityEvent{
+		TenantID:  user.TenantID,
+		Type:      model.SecurityEventRoleChange,
+		Success:   true,
+		UserID:    &user.ID,
+		Username:  user.Username,
+		Actor:     model.ActorFromContext(ctx),
+		Details:   truncate(formatRoles(previousRoles)+" -> "+formatRoles(user.RoleNames()), 1000),
+		IPAddress: truncate(client.IPAddress, 64),
+		UserAgent: truncate(client.UserAgent, 512),
+	})
+}
+
+// ListEvents returns the security events of the caller's organization matching the query.
+// Callers holding organizations:manage see the events of every organization and of unknown users.
+func (s *SecurityEventService) ListEvents(ctx context.Context, query *model.SecurityEventQuery, canManageOrganizations bool) ([]model.SecurityEvent, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultSecurityEventLimit
+	}
+	return s.eventRepo.Find(ctx, query, canManageOrganizations)
+}
+
+func (s *SecurityEventService) store(event *model.SecurityEvent) {
+	if err := s.eventRepo.Create(event); err != nil {
+		log.Printf("Error recording %s security event for %q: %v", event.Type, event.Username, err)
+	}
+}
+
+func formatRoles(roles []string) string {
+	if len(roles) == 0 {
+		return "(none)"
+	}
+	return strings.Join(roles, ", ")
+}
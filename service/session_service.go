@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+type SessionService struct {
+	sessionRepo  repository.SessionRepository
+	userRepo     *repository.UserRepository
+	tokenService *TokenService
+}
+
+func NewSessionService(sessionRepo repository.SessionRepository, userRepo *repository.UserRepository, tokenService *TokenService) *SessionService {
+	return &SessionService{sessionRepo: sessionRepo, userRepo: userRepo, tokenService: tokenService}
+}
+
+// ListSessions returns the active sessions of a user and marks the one with currentSessionID as current
+func (s *SessionService) ListSessions(userID uint, currentSessionID string) ([]model.SessionResponse, error) {
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, model.SessionResponse{
+			ID:             session.ID,
+			Device:         utils.DescribeUserAgent(session.UserAgent),
+			UserAgent:      session.UserAgent,
+			IPAddress:      session.IPAddress,
+			CreatedAt:      session.CreatedAt,
+			LastActivityAt: session.LastActivityAt,
+			ExpiresAt:      session.ExpiresAt,
+			Current:        currentSessionID != "" && session.SessionID == currentSessionID,
+		})
+	}
+	return responses, nil
+}
+
+// RevokeSession signs a single device of the user out. Sessions of other users are reported as not found.
+func (s *SessionService) RevokeSession(userID, id uint) error {
+	session, err := s.sessionRepo.FindByID(id)
+	if err != nil || session.UserID != userID || session.RevokedAt != nil {
+		return errors.New("session not found")
+	}
+	return s.tokenService.RevokeSession(session.SessionID)
+}
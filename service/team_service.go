@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+)
+
+type TeamService struct {
+	teamRepo repository.TeamRepository
+	roleRepo repository.RoleRepository
+	userRepo *repository.UserRepository
+}
+
+func NewTeamService(teamRepo repository.TeamRepository, roleRepo repository.RoleRepository, userRepo *repository.UserRepository) *TeamService {
+	return &TeamService{teamRepo: teamRepo, roleRepo: roleRepo, userRepo: userRepo}
+}
+
+// ListTeams returns the teams of the caller's organization
+func (s *TeamService) ListTeams(ctx context.Context) ([]model.TeamResponse, error) {
+	teams, err := s.teamRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.TeamResponse, 0, len(teams))
+	for _, team := range teams {
+		responses = append(responses, team.ToTeamResponse())
+	}
+	return responses, nil
+}
+
+func (s *TeamService) GetTeam(ctx context.Context, id uint) (*model.TeamResponse, error) {
+	team, err := s.teamRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	response := team.ToTeamResponse()
+	return &response, nil
+}
+
+// CreateTeam creates a team in the caller's organization. Only callers holding organizations:manage may grant it.
+func (s *TeamService) CreateTeam(ctx context.Context, req *model.CreateTeamRequest, canManageOrganizations bool) (*model.TeamResponse, error) {
+	if _, err := s.teamRepo.FindByName(ctx, req.Name); err == nil {
+		return nil, errors.New("team name already exists")
+	}
+
+	permissions, err := resolvePermissions(s.roleRepo, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(permissions) {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	team := &model.Team{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	if err := s.teamRepo.Create(ctx, team); err != nil {
+		return nil, err
+	}
+
+	response := team.ToTeamResponse()
+	return &response, nil
+}
+
+// UpdateTeam changes the name, description or permissions of a team. Members get changed permissions
+// with their next request, their tokens stay valid.
+func (s *TeamService) UpdateTeam(ctx context.Context, id uint, req *model.UpdateTeamRequest, canManageOrganizations bool) (*model.TeamResponse, error) {
+	team, err := s.teamRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(team.Permissions) {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	if req.Name != nil || req.Description != nil {
+		if req.Name != nil {
+			if existing, err := s.teamRepo.FindByName(ctx, *req.Name); err == nil && existing.ID != team.ID {
+				return nil, errors.New("team name already exists")
+			}
+			team.Name = *req.Name
+		}
+		if req.Description != nil {
+			team.Description = *req.Description
+		}
+		if err := s.teamRepo.Update(team); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Permissions != nil {
+		permissions, err := resolvePermissions(s.roleRepo, *req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if !canManageOrganizations && grantsOrganizationsManage(permissions) {
+			return nil, errors.New("insufficient permissions")
+		}
+		if err := s.teamRepo.ReplacePermissions(team, permissions); err != nil {
+			return nil, err
+		}
+		team.Permissions = permissions
+	}
+
+	response := team.ToTeamResponse()
+	return &response, nil
+}
+
+// DeleteTeam removes a team of the caller's organization. Topics it owned no longer have an owning team.
+func (s *TeamService) DeleteTeam(ctx context.Context, id uint, canManageOrganizations bool) error {
+	team, err := s.teamRepo.FindByID(ctx, id)
+	if err != nil {
+		return errors.New("team not found")
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(team.Permissions) {
+		return errors.New("insufficient permissions")
+	}
+	return s.teamRepo.Delete(team.ID)
+}
+
+// AddMember adds a user of the caller's organization to a team
+func (s *TeamService) AddMember(ctx context.Context, teamID, userID uint, canManageOrganizations bool) (*model.TeamResponse, error) {
+	team, err := s.teamRepo.FindByID(ctx, teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	if !canManageOrganizations && grantsOrganizationsManage(team.Permissions) {
+		return nil, errors.New("insufficient permissions")
+	}
+	if !s.userRepo.ExistsInTenant(ctx, userID) {
+		return nil, errors.New("user not found")
+	}
+
+	if err := s.teamRepo.AddMember(team.ID, userID); err != nil {
+		return nil, err
+	}
+	return s.GetTeam(ctx, team.ID)
+}
+
+// RemoveMember removes a user from a team
+func (s *TeamService) RemoveMember(ctx context.Context, teamID, userID uint) (*model.TeamResponse, error) {
+	team, err := s.teamRepo.FindByID(ctx, teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+
+	if err := s.teamRepo.RemoveMember(team.ID, userID); err != nil {
+		return nil, err
+	}
+	return s.GetTeam(ctx, team.ID)
+}
+
+// ResolveGroups returns the teams of a user and the permissions granted through them
+func (s *TeamService) ResolveGroups(userID uint) (*model.UserGroups, error) {
+	teams, err := s.teamRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewUserGroups(teams), nil
+}
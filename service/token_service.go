@@ -0,0 +1,399 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+// sessionTouchInterval limits how often the last activity of a session is written
+const sessionTouchInterval = time.Minute
+
+type TokenService struct {
+	userRepo         *repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revocationRepo   repository.TokenRevocationRepository
+	sessionRepo      repository.SessionRepository
+	securityEvents   *SecurityEventService
+
+	touchMu     sync.Mutex
+	lastTouched map[string]time.Time
+}
+
+func NewTokenService(userRepo *repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, revocationRepo repository.TokenRevocationRepository, sessionRepo repository.SessionRepository, securityEvents *SecurityEventService) *TokenService {
+	return &TokenService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revocationRepo:   revocationRepo,
+		sessionRepo:      sessionRepo,
+		securityEvents:   securityEvents,
+		lastTouched:      make(map[string]time.Time),
+	}
+}
+
+// IssueTokens issues an access token and a refresh token starting a new token family,
+// and records the family as a session of the client
+func (s *TokenService) IssueTokens(user *model.User, client model.ClientInfo) (*model.LoginResponse, error) {
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	response, expiresAt, err := s.issueTokens(user, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.sessionRepo.Create(&model.Session{
+		SessionID:      familyID,
+		UserID:         user.ID,
+		UserAgent:      truncate(client.UserAgent, 512),
+		IPAddress:      truncate(client.IPAddress, 64),
+		LastActivityAt: now,
+		ExpiresAt:      expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new token pair.
+// Every refresh token can be used only once; presenting a used token again revokes its whole family.
+func (s *TokenService) RefreshTokens(refreshToken string, client model.ClientInfo) (*model.LoginResponse, error) {
+	stored, err := s.refreshTokenRepo.FindByTokenHash(utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, s.refreshFailed(nil, client, model.SecurityReasonInvalidToken, errors.New("invalid refresh token"))
+	}
+
+	now := time.Now()
+	if stored.RevokedAt != nil {
+		return nil, s.refreshFailed(stored, client, model.SecurityReasonRevokedToken, errors.New("refresh token has been revoked"))
+	}
+	if stored.UsedAt != nil {
+		return nil, s.refreshFailed(stored, client, model.SecurityReasonRefreshTokenReuse, s.handleReuse(stored.FamilyID))
+	}
+	if now.After(stored.ExpiresAt) {
+		return nil, s.refreshFailed(stored, client, model.SecurityReasonExpiredToken, errors.New("refresh token expired"))
+	}
+
+	// Mark the token as used before issuing a new one so a concurrent refresh with the same token loses
+	marked, err := s.refreshTokenRepo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, s.refreshFailed(stored, client, model.SecurityReasonRefreshTokenReuse, s.handleReuse(stored.FamilyID))
+	}
+
+	user, err := s.userRepo.GetUserByID(stored.UserID)
+	if err != nil {
+		return nil, s.refreshFailed(stored, client, model.SecurityReasonUnknownUser, errors.New("invalid refresh token"))
+	}
+
+	if !user.IsActive {
+		if err := s.RevokeSession(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		s.securityEvents.Record(model.SecurityEventTokenRefresh, user, user.Username, client, model.SecurityReasonInactiveAccount)
+		return nil, errors.New("account is deactivated")
+	}
+
+	response, expiresAt, err := s.issueTokens(user, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	client.UserAgent = truncate(client.UserAgent, 512)
+	client.IPAddress = truncate(client.IPAddress, 64)
+	if err := s.sessionRepo.UpdateActivity(stored.FamilyID, client, now, expiresAt); err != nil {
+		return nil, err
+	}
+
+	s.securityEvents.Record(model.SecurityEventTokenRefresh, user, user.Username, client, "")
+	return response, nil
+}
+
+// refreshFailed records a failed refresh for the owner of the refresh token, when it was found, and returns err
+func (s *TokenService) refreshFailed(stored *model.RefreshToken, client model.ClientInfo, reason string, err error) error {
+	var user *model.User
+	if stored != nil {
+		if owner, findErr := s.userRepo.GetUserByID(stored.UserID); findErr == nil {
+			user = owner
+		}
+	}
+	s.securityEvents.Record(model.SecurityEventTokenRefresh, user, "", client, reason)
+	return err
+}
+
+// ValidateAccessToken validates an access token and checks it against the revocation store
+func (s *TokenService) ValidateAccessToken(tokenString string) (*model.Claims, error) {
+	return s.validateToken(tokenString, "")
+}
+
+// ValidateChallengeToken validates a two-factor login challenge and checks it against the revocation store
+func (s *TokenService) ValidateChallengeToken(tokenString string) (*model.Claims, error) {
+	return s.validateToken(tokenString, model.TokenPurposeTwoFactorChallenge)
+}
+
+// RevokeToken revokes a single token until it expires, for example a challenge that has been used
+func (s *TokenService) RevokeToken(claims *model.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	return s.revocationRepo.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+}
+
+func (s *TokenService) validateToken(tokenString, purpose string) (*model.Claims, error) {
+	claims, err := config.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens issued for another purpose must never be accepted in place of each other
+	if claims.Purpose != purpose {
+		return nil, errors.New("invalid token type")
+	}
+
+	if claims.ID != "" {
+		revoked, err := s.revocationRepo.IsTokenRevoked(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
+	}
+
+	if claims.SessionID != "" {
+		revoked, err := s.revocationRepo.IsTokenRevoked(sessionRevocationKey(claims.SessionID))
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
+	}
+
+	// Client tokens have no user; they are revoked together with their client
+	if claims.ClientID != "" {
+		revoked, err := s.revocationRepo.IsTokenRevoked(clientRevocationKey(claims.ClientID))
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
+		return claims, nil
+	}
+
+	if err := s.checkUserRevocation(claims.UserID, claims); err != nil {
+		return nil, err
+	}
+
+	// An impersonation also ends when the admin is signed out everywhere, e.g. on deactivation
+	if claims.Act != nil {
+		if err := s.checkUserRevocation(claims.Act.UserID, claims); err != nil {
+			return nil, err
+		}
+		return claims, nil
+	}
+
+	if claims.SessionID != "" {
+		s.touchSession(claims.SessionID)
+	}
+
+	return claims, nil
+}
+
+// TwoFactorSetupPending reports whether a user still has to enroll in the 2FA a role of theirs requires.
+// Access tokens carry this from the time they were issued, so it is looked up again once the user may have enrolled.
+func (s *TokenService) TwoFactorSetupPending(userID uint) (bool, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user.RequiresTwoFactor() && !user.TwoFactorEnabled, nil
+}
+
+// checkUserRevocation rejects claims issued before the per-user revocation timestamp of userID
+func (s *TokenService) checkUserRevocation(userID uint, claims *model.Claims) error {
+	revokedBefore, err := s.revocationRepo.GetUserTokensRevokedBefore(userID)
+	if err != nil {
+		return err
+	}
+	if revokedBefore != nil && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*revokedBefore)) {
+		return errors.New("token has been revoked")
+	}
+	return nil
+}
+
+// Logout revokes the presented access token and the session it was issued with
+func (s *TokenService) Logout(claims *model.Claims, client model.ClientInfo) error {
+	if claims.ID != "" {
+		if err := s.revocationRepo.RevokeToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != "" {
+		if err := s.RevokeSession(claims.SessionID); err != nil {
+			return err
+		}
+	}
+
+	// OAuth client tokens have no user to record the logout for
+	if claims.ClientID == "" {
+		user := &model.User{ID: claims.UserID, Username: claims.Username, TenantID: claims.TenantID}
+		s.securityEvents.Record(model.SecurityEventLogout, user, user.Username, client, "")
+	}
+	return nil
+}
+
+// RevokeSession ends a single session: its refresh tokens stop working and so do the access tokens
+// already issued with it
+func (s *TokenService) RevokeSession(sessionID string) error {
+	now := time.Now()
+	if err := s.refreshTokenRepo.RevokeFamily(sessionID, now); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.Revoke(sessionID, now); err != nil {
+		return err
+	}
+	return s.revocationRepo.RevokeToken(sessionRevocationKey(sessionID), now.Add(config.AccessTokenTTL))
+}
+
+// RevokeAllUserSessions revokes every access and refresh token issued to a user so far
+func (s *TokenService) RevokeAllUserSessions(userID uint) error {
+	now := time.Now()
+	if err := s.revokeUserTokens(userID, now); err != nil {
+		return err
+	}
+	if err := s.refreshTokenRepo.RevokeAllByUserID(userID, now); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAllByUserID(userID, now)
+}
+
+// InvalidateAccessTokens makes every access token issued to a user so far invalid while keeping
+// their sessions. Clients refresh and get a token reflecting the current roles and permissions.
+func (s *TokenService) InvalidateAccessTokens(userID uint) error {
+	return s.revokeUserTokens(userID, time.Now())
+}
+
+// revokeUserTokens revokes every token issued to a user up to now. JWT timestamps have second precision,
+// so the revocation is rounded up to the next full second to also cover tokens issued earlier in the
+// current one. Tokens issued after it get the revocation time as their issue time, see issueTime.
+func (s *TokenService) revokeUserTokens(userID uint, now time.Time) error {
+	before := now.Truncate(time.Second)
+	if before.Before(now) {
+		before = before.Add(time.Second)
+	}
+	return s.revocationRepo.RevokeUserTokens(userID, before)
+}
+
+// issueTime returns the issue time for a new token of a user: the current time, or the user's revocation
+// time while that still lies ahead, so a token issued right after a revocation (for example after a
+// password change) is not covered by it
+func (s *TokenService) issueTime(userID uint) time.Time {
+	now := time.Now()
+	revokedBefore, err := s.revocationRepo.GetUserTokensRevokedBefore(userID)
+	if err != nil {
+		log.Printf("Error reading token revocation of user %d: %v", userID, err)
+		return now
+	}
+	if revokedBefore != nil && now.Before(*revokedBefore) {
+		return *revokedBefore
+	}
+	return now
+}
+
+// handleReuse revokes a token family after one of its already rotated tokens was presented again
+func (s *TokenService) handleReuse(familyID string) error {
+	if err := s.RevokeSession(familyID); err != nil {
+		return err
+	}
+	return errors.New("refresh token reuse detected")
+}
+
+// touchSession records activity on a session at most once per sessionTouchInterval per instance
+func (s *TokenService) touchSession(sessionID string) {
+	now := time.Now()
+
+	s.touchMu.Lock()
+	if now.Sub(s.lastTouched[sessionID]) < sessionTouchInterval {
+		s.touchMu.Unlock()
+		return
+	}
+	s.lastTouched[sessionID] = now
+	if len(s.lastTouched) > 10000 {
+		for id, at := range s.lastTouched {
+			if now.Sub(at) >= sessionTouchInterval {
+				delete(s.lastTouched, id)
+			}
+		}
+	}
+	s.touchMu.Unlock()
+
+	if err := s.sessionRepo.Touch(sessionID, now); err != nil {
+		log.Printf("Error recording activity of session: %v", err)
+	}
+}
+
+func (s *TokenService) issueTokens(user *model.User, familyID string) (*model.LoginResponse, time.Time, error) {
+	token, err := config.GenerateToken(user, familyID, s.issueTime(user.ID))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	refreshToken, expiresAt, err := config.GenerateRefreshToken()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if err := s.refreshTokenRepo.Create(&model.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	response := user.ToUserResponse()
+	return &model.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         &response,
+	}, expiresAt, nil
+}
+
+// RevokeClientTokens invalidates every access token issued to an OAuth client.
+// ttl is the lifetime of client tokens, after which the revocation entry is no longer needed.
+func (s *TokenService) RevokeClientTokens(clientID string, ttl time.Duration) error {
+	return s.revocationRepo.RevokeToken(clientRevocationKey(clientID), time.Now().Add(ttl))
+}
+
+// sessionRevocationKey is the revocation store key that invalidates every access token of a session
+func sessionRevocationKey(sessionID string) string {
+	return "sid:" + sessionID
+}
+
+// clientRevocationKey is the revocation store key that invalidates every access token of an OAuth client
+func clientRevocationKey(clientID string) string {
+	return "client:" + clientID
+}
+
+func truncate(value string, maxLen int) string {
+	if len(value) > maxLen {
+		return value[:maxLen]
+	}
+	return value
+}
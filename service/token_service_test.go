@@ -0,0 +1,232 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type fakeRefreshTokenRepository struct {
+	tokens          map[string]*model.RefreshToken
+	markUsedResult  bool
+	revokedFamilies []string
+}
+
+func (r *fakeRefreshTokenRepository) Create(token *model.RefreshToken) error {
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) FindByTokenHash(tokenHash string) (*model.RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return token, nil
+}
+
+func (r *fakeRefreshTokenRepository) MarkUsed(id uint, usedAt time.Time) (bool, error) {
+	return r.markUsedResult, nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeFamily(familyID string, revokedAt time.Time) error {
+	r.revokedFamilies = append(r.revokedFamilies, familyID)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeAllByUserID(userID uint, revokedAt time.Time) error {
+	return nil
+}
+
+// fakeSessionRepository only implements what token refreshes use
+type fakeSessionRepository struct {
+	repository.SessionRepository
+	revokedSessions []string
+}
+
+func (r *fakeSessionRepository) Revoke(sessionID string, revokedAt time.Time) error {
+	r.revokedSessions = append(r.revokedSessions, sessionID)
+	return nil
+}
+
+func (r *fakeSessionRepository) RevokeAllByUserID(userID uint, revokedAt time.Time) error {
+	return nil
+}
+
+// fakeSecurityEventRepository only implements what recording events uses
+type fakeSecurityEventRepository struct {
+	repository.SecurityEventRepository
+	events []model.SecurityEvent
+}
+
+func (r *fakeSecurityEventRepository) Create(event *model.SecurityEvent) error {
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func TestRefreshTokensReuse(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name           string
+		usedAt         *time.Time
+		revokedAt      *time.Time
+		markUsedResult bool
+		wantErr        string
+		wantRevoked    bool
+	}{
+		{
+			name:        "rotated token presented again",
+			usedAt:      &usedAt,
+			wantErr:     "refresh token reuse detected",
+			wantRevoked: true,
+		},
+		{
+			name:           "concurrent refresh with the same token",
+			markUsedResult: false,
+			wantErr:        "refresh token reuse detected",
+			wantRevoked:    true,
+		},
+		{
+			name:      "token of a revoked family",
+			revokedAt: &revokedAt,
+			wantErr:   "refresh token has been revoked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+
+			refreshToken := "refresh-token"
+			refreshRepo := &fakeRefreshTokenRepository{
+				tokens: map[string]*model.RefreshToken{
+					utils.HashToken(refreshToken): {
+						ID:        1,
+						UserID:    2,
+						TokenHash: utils.HashToken(refreshToken),
+						FamilyID:  "family-1",
+						ExpiresAt: time.Now().Add(time.Hour),
+						UsedAt:    tt.usedAt,
+						RevokedAt: tt.revokedAt,
+					},
+				},
+				markUsedResult: tt.markUsedResult,
+			}
+			sessionRepo := &fakeSessionRepository{}
+			revocationRepo := repository.NewMemoryTokenRevocationRepository()
+			eventRepo := &fakeSecurityEventRepository{}
+			tokenService := NewTokenService(repository.NewUserRepository(db), refreshRepo, revocationRepo, sessionRepo, NewSecurityEventService(eventRepo))
+
+			_, err := tokenService.RefreshTokens(refreshToken, model.ClientInfo{IPAddress: "203.0.113.10"})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("RefreshTokens() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if got := slices.Contains(refreshRepo.revokedFamilies, "family-1"); got != tt.wantRevoked {
+				t.Errorf("family revoked = %v, want %v", got, tt.wantRevoked)
+			}
+			if got := slices.Contains(sessionRepo.revokedSessions, "family-1"); got != tt.wantRevoked {
+				t.Errorf("session revoked = %v, want %v", got, tt.wantRevoked)
+			}
+			// Access tokens already issued with the family stop working as well
+			revoked, err := revocationRepo.IsTokenRevoked(sessionRevocationKey("family-1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("access tokens of the session revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+
+			if len(eventRepo.events) != 1 || eventRepo.events[0].Success {
+				t.Fatalf("security events = %+v, want one failed refresh", eventRepo.events)
+			}
+		})
+	}
+}
+
+func TestRevokeUserTokensRoundsUp(t *testing.T) {
+	second := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{name: "within a second", now: second.Add(300 * time.Millisecond), want: second.Add(time.Second)},
+		{name: "on a full second", now: second, want: second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revocationRepo := repository.NewMemoryTokenRevocationRepository()
+			tokenService := &TokenService{revocationRepo: revocationRepo}
+
+			if err := tokenService.revokeUserTokens(2, tt.now); err != nil {
+				t.Fatal(err)
+			}
+			before, err := revocationRepo.GetUserTokensRevokedBefore(2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if before == nil || !before.Equal(tt.want) {
+				t.Errorf("revoked before = %v, want %v", before, tt.want)
+			}
+
+			// A token issued earlier in the same second carries that second as its issue time
+			claims := &model.Claims{}
+			claims.IssuedAt = jwt.NewNumericDate(tt.now.Add(-100 * time.Millisecond))
+			if err := tokenService.checkUserRevocation(2, claims); err == nil {
+				t.Error("token issued before the revocation is still valid")
+			}
+		})
+	}
+}
+
+func TestIssueTimeAfterRevocation(t *testing.T) {
+	tests := []struct {
+		name          string
+		revokedBefore *time.Time
+		wantRevoked   bool
+	}{
+		{name: "no revocation"},
+		{name: "revocation in the past", revokedBefore: ptrTime(time.Now().Add(-time.Hour).Truncate(time.Second))},
+		{name: "revocation still ahead", revokedBefore: ptrTime(time.Now().Truncate(time.Second).Add(2 * time.Second)), wantRevoked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revocationRepo := repository.NewMemoryTokenRevocationRepository()
+			if tt.revokedBefore != nil {
+				if err := revocationRepo.RevokeUserTokens(2, *tt.revokedBefore); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tokenService := &TokenService{revocationRepo: revocationRepo}
+
+			issuedAt := tokenService.issueTime(2)
+			if tt.wantRevoked && !issuedAt.Equal(*tt.revokedBefore) {
+				t.Errorf("issue time = %v, want the revocation time %v", issuedAt, *tt.revokedBefore)
+			}
+
+			// Tokens issued after the revocation stay valid
+			claims := &model.Claims{}
+			claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+			if err := tokenService.checkUserRevocation(2, claims); err != nil {
+				t.Errorf("checkUserRevocation() error = %v", err)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -1,33 +1,53 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go-gin-gorm-backend/model"
 	"go-gin-gorm-backend/repository"
 	"go-gin-gorm-backend/utils"
+	"log"
 	"strconv"
 	"strings"
 )
 
 type TopicDetailService interface {
-	CreateTopicDetail(detail *model.TopicDetail) error
-	CreateTopicDetailWithValidation(topicID string, detailRequest *model.CreateTopicDetailRequest) (*model.TopicDetail, error)
-	GetAllDetailsByTopicID(topicID string) ([]model.TopicDetail, error)
-	GetDetailByID(id string) (*model.TopicDetail, error)
-	UpdateTopicDetail(detail *model.TopicDetail) error
-	UpdateTopicDetailWithValidation(id string, detailRequest *model.UpdateTopicDetailRequest) (*model.TopicDetail, error)
-	DeleteTopicDetail(id string) error
-	GetNextDetailOrder(topicID string) (int, error)
-	MoveTopicDetailToPosition(detailID uint, newOrder int) error
-	ValidateTopicDetailName(name string, excludeID uint) error
+	CreateTopicDetail(ctx context.Context, detail *model.TopicDetail) error
+	CreateTopicDetailWithValidation(ctx context.Context, topicID string, detailRequest *model.CreateTopicDetailRequest) (*model.TopicDetail, error)
+	GetAllDetailsByTopicID(ctx context.Context, topicID string) ([]model.TopicDetail, error)
+	GetDetailByID(ctx context.Context, id string) (*model.TopicDetail, error)
+	UpdateTopicDetail(ctx context.Context, detail *model.TopicDetail) error
+	UpdateTopicDetailWithValidation(ctx context.Context, id string, detailRequest *model.UpdateTopicDetailRequest) (*model.TopicDetail, error)
+	DeleteTopicDetail(ctx context.Context, id string) error
+	GetNextDetailOrder(ctx context.Context, topicID string) (int, error)
+	MoveTopicDetailToPosition(ctx context.Context, detailID uint, newOrder int) error
+	ValidateTopicDetailName(ctx context.Context, name string, excludeID uint) error
 }
 
 type topicDetailService struct {
 	topicDetailRepo repository.TopicDetailRepository
+	topicRepo       repository.TopicRepository
+	aclRepo         repository.TopicACLRepository
 }
 
-func NewTopicDetailService(topicDetailRepo repository.TopicDetailRepository) TopicDetailService {
-	return &topicDetailService{topicDetailRepo}
+func NewTopicDetailService(topicDetailRepo repository.TopicDetailRepository, topicRepo repository.TopicRepository, aclRepo repository.TopicACLRepository) TopicDetailService {
+	return &topicDetailService{topicDetailRepo, topicRepo, aclRepo}
+}
+
+// checkDetailAccess applies the access control of the topic to its details.
+// Callers that cannot read the topic are told the detail does not exist.
+func (s *topicDetailService) checkDetailAccess(ctx context.Context, detail *model.TopicDetail, required string) error {
+	topic, err := s.topicRepo.FindByID(ctx, detail.TopicID)
+	if err != nil {
+		return errors.New("topic detail not found")
+	}
+	if err := checkTopicAccess(ctx, s.aclRepo, topic, required); err != nil {
+		if err.Error() == "topic not found" {
+			return errors.New("topic detail not found")
+		}
+		return err
+	}
+	return nil
 }
 
 func (s *topicDetailService) handleDuplicateOrderError(err error) error {
@@ -60,8 +80,8 @@ func (s *topicDetailService) handleDuplicateNameError(err error) error {
 	return err
 }
 
-func (s *topicDetailService) GetNextDetailOrder(topicID string) (int, error) {
-	details, err := s.GetAllDetailsByTopicID(topicID)
+func (s *topicDetailService) GetNextDetailOrder(ctx context.Context, topicID string) (int, error) {
+	details, err := s.findDetailsByTopicID(ctx, topicID)
 	if err != nil {
 		return 0, err
 	}
@@ -69,8 +89,10 @@ func (s *topicDetailService) GetNextDetailOrder(topicID string) (int, error) {
 	return utils.GetNextOrder(details, func(d model.TopicDetail) int { return d.Order }), nil
 }
 
-func (s *topicDetailService) ValidateTopicDetailName(name string, excludeID uint) error {
-	existingDetail, err := s.topicDetailRepo.FindByName(name)
+// ValidateTopicDetailName fails when another detail of the organization has the name. A detail under a topic
+// the caller cannot read is not named as such, the name is only reported as unavailable.
+func (s *topicDetailService) ValidateTopicDetailName(ctx context.Context, name string, excludeID uint) error {
+	existingDetail, err := s.topicDetailRepo.FindByName(ctx, name)
 	if err != nil {
 		// If error is "record not found", then the name is available
 		if err.Error() == "record not found" {
@@ -84,11 +106,14 @@ func (s *topicDetailService) ValidateTopicDetailName(name string, excludeID uint
 		return nil
 	}
 
+	if err := s.checkDetailAccess(ctx, existingDetail, model.TopicAccessRead); err != nil {
+		return errors.New("topic detail name is not available")
+	}
 	return errors.New("topic detail name already exists")
 }
 
-func (s *topicDetailService) CreateTopicDetail(detail *model.TopicDetail) error {
-	err := s.topicDetailRepo.Create(detail)
+func (s *topicDetailService) CreateTopicDetail(ctx context.Context, detail *model.TopicDetail) error {
+	err := s.topicDetailRepo.Create(ctx, detail)
 	if err != nil {
 		// Check for duplicate name error first
 		if s.handleDuplicateNameError(err).Error() == "topic detail name already exists" {
@@ -100,78 +125,134 @@ func (s *topicDetailService) CreateTopicDetail(detail *model.TopicDetail) error
 }
 
 // CreateTopicDetailWithValidation handles all business logic for creating a topic detail
-func (s *topicDetailService) CreateTopicDetailWithValidation(topicID string, detailRequest *model.CreateTopicDetailRequest) (*model.TopicDetail, error) {
+func (s *topicDetailService) CreateTopicDetailWithValidation(ctx context.Context, topicID string, detailRequest *model.CreateTopicDetailRequest) (*model.TopicDetail, error) {
 	// Convert string to uint
 	topicIDUint, err := strconv.ParseUint(topicID, 10, 32)
 	if err != nil {
 		return nil, errors.New("invalid topic ID format")
 	}
 
+	// The topic has to exist in the organization of the caller
+	topic, err := s.topicRepo.FindByID(ctx, uint(topicIDUint))
+	if err != nil {
+		return nil, errors.New("topic not found")
+	}
+	if err := checkTopicAccess(ctx, s.aclRepo, topic, model.TopicAccessWrite); err != nil {
+		return nil, err
+	}
+
 	// Validate topic detail name uniqueness
-	if err := s.ValidateTopicDetailName(detailRequest.Name, 0); err != nil {
+	if err := s.ValidateTopicDetailName(ctx, detailRequest.Name, 0); err != nil {
 		return nil, err
 	}
 
 	// Get the next order number for this topic
-	nextOrder, err := s.GetNextDetailOrder(topicID)
+	nextOrder, err := s.GetNextDetailOrder(ctx, topicID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create detail with hardcoded values
+	// Stamp the authenticated user as creator
+	actor := model.ActorFromContext(ctx)
 	detail := &model.TopicDetail{
 		TopicID:   uint(topicIDUint),
 		Name:      detailRequest.Name,
 		Order:     nextOrder,
-		CreatedBy: "admin",
-		UpdatedBy: "admin",
+		CreatedBy: actor,
+		UpdatedBy: actor,
 	}
 
 	// Create the detail
-	if err := s.CreateTopicDetail(detail); err != nil {
+	if err := s.CreateTopicDetail(ctx, detail); err != nil {
 		return nil, err
 	}
 
 	return detail, nil
 }
 
-func (s *topicDetailService) GetAllDetailsByTopicID(topicID string) ([]model.TopicDetail, error) {
+// GetAllDetailsByTopicID returns the details of a topic the caller can read
+func (s *topicDetailService) GetAllDetailsByTopicID(ctx context.Context, topicID string) ([]model.TopicDetail, error) {
+	// Convert string to uint
+	topicIDUint, err := strconv.ParseUint(topicID, 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid topic ID format")
+	}
+
+	topic, err := s.topicRepo.FindByID(ctx, uint(topicIDUint))
+	if err != nil {
+		return nil, errors.New("topic not found")
+	}
+	if err := checkTopicAccess(ctx, s.aclRepo, topic, model.TopicAccessRead); err != nil {
+		return nil, err
+	}
+	return s.topicDetailRepo.FindAllByTopicID(ctx, topic.ID)
+}
+
+// findDetailsByTopicID looks up the details of a topic without checking the access of the caller
+func (s *topicDetailService) findDetailsByTopicID(ctx context.Context, topicID string) ([]model.TopicDetail, error) {
 	// Convert string to uint
 	topicIDUint, err := strconv.ParseUint(topicID, 10, 32)
 	if err != nil {
 		return nil, err
 	}
-	return s.topicDetailRepo.FindAllByTopicID(uint(topicIDUint))
+	return s.topicDetailRepo.FindAllByTopicID(ctx, uint(topicIDUint))
 }
 
-func (s *topicDetailService) GetDetailByID(id string) (*model.TopicDetail, error) {
+func (s *topicDetailService) GetDetailByID(ctx context.Context, id string) (*model.TopicDetail, error) {
+	detail, err := s.findDetail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDetailAccess(ctx, detail, model.TopicAccessRead); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// findDetail looks up a detail without checking the access of the caller
+func (s *topicDetailService) findDetail(ctx context.Context, id string) (*model.TopicDetail, error) {
 	// Convert string to uint
 	idUint, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return nil, err
 	}
-	return s.topicDetailRepo.FindByID(uint(idUint))
+	return s.topicDetailRepo.FindByID(ctx, uint(idUint))
 }
 
-func (s *topicDetailService) DeleteTopicDetail(id string) error {
+func (s *topicDetailService) DeleteTopicDetail(ctx context.Context, id string) error {
 	// Convert string to uint
 	idUint, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return err
 	}
-	return s.topicDetailRepo.Delete(uint(idUint))
+
+	detail, err := s.topicDetailRepo.FindByID(ctx, uint(idUint))
+	if err != nil {
+		return errors.New("topic detail not found")
+	}
+	if err := s.checkDetailAccess(ctx, detail, model.TopicAccessWrite); err != nil {
+		return err
+	}
+
+	if err := s.topicDetailRepo.Delete(ctx, uint(idUint)); err != nil {
+		return err
+	}
+
+	// The row is gone, so the actor is only kept in the log
+	log.Printf("Topic detail %d deleted by %s", idUint, model.ActorFromContext(ctx))
+	return nil
 }
 
 // MoveTopicDetailToPosition moves a specific topic detail to a new position and reorders all details accordingly
-func (s *topicDetailService) MoveTopicDetailToPosition(detailID uint, newOrder int) error {
+func (s *topicDetailService) MoveTopicDetailToPosition(ctx context.Context, detailID uint, newOrder int) error {
 	// First, get the topic detail to find its topic ID
-	detail, err := s.topicDetailRepo.FindByID(detailID)
+	detail, err := s.topicDetailRepo.FindByID(ctx, detailID)
 	if err != nil {
 		return err
 	}
 
 	// Get all details for this topic
-	details, err := s.GetAllDetailsByTopicID(strconv.FormatUint(uint64(detail.TopicID), 10))
+	details, err := s.findDetailsByTopicID(ctx, strconv.FormatUint(uint64(detail.TopicID), 10))
 	if err != nil {
 		return err
 	}
@@ -183,9 +264,14 @@ func (s *topicDetailService) MoveTopicDetailToPosition(detailID uint, newOrder i
 		func(d model.TopicDetail) interface{} { return d.ID },
 		detailID, newOrder)
 
-	// Update all details in the database
-	for _, detail := range reorderedDetails {
-		if err := s.topicDetailRepo.Update(&detail); err != nil {
+	// Update the details whose position changed in the database
+	actor := model.ActorFromContext(ctx)
+	for i, detail := range reorderedDetails {
+		if detail.Order == details[i].Order {
+			continue
+		}
+		detail.UpdatedBy = actor
+		if err := s.topicDetailRepo.Update(ctx, &detail); err != nil {
 			return err
 		}
 	}
@@ -193,8 +279,8 @@ func (s *topicDetailService) MoveTopicDetailToPosition(detailID uint, newOrder i
 	return nil
 }
 
-func (s *topicDetailService) UpdateTopicDetail(detail *model.TopicDetail) error {
-	err := s.topicDetailRepo.Update(detail)
+func (s *topicDetailService) UpdateTopicDetail(ctx context.Context, detail *model.TopicDetail) error {
+	err := s.topicDetailRepo.Update(ctx, detail)
 	if err != nil {
 		// Check for duplicate name error first
 		if s.handleDuplicateNameError(err).Error() == "topic detail name already exists" {
@@ -206,42 +292,44 @@ func (s *topicDetailService) UpdateTopicDetail(detail *model.TopicDetail) error
 }
 
 // UpdateTopicDetailWithValidation handles all business logic for updating a topic detail
-func (s *topicDetailService) UpdateTopicDetailWithValidation(id string, detailRequest *model.UpdateTopicDetailRequest) (*model.TopicDetail, error) {
+func (s *topicDetailService) UpdateTopicDetailWithValidation(ctx context.Context, id string, detailRequest *model.UpdateTopicDetailRequest) (*model.TopicDetail, error) {
 	// Get existing detail to preserve fields
-	existingDetail, err := s.GetDetailByID(id)
+	existingDetail, err := s.findDetail(ctx, id)
 	if err != nil {
 		return nil, errors.New("topic detail not found")
 	}
+	if err := s.checkDetailAccess(ctx, existingDetail, model.TopicAccessWrite); err != nil {
+		return nil, err
+	}
 
 	// Update only provided fields
 	if detailRequest.Name != nil {
 		// Validate topic detail name uniqueness (excluding current detail)
-		if err := s.ValidateTopicDetailName(*detailRequest.Name, existingDetail.ID); err != nil {
+		if err := s.ValidateTopicDetailName(ctx, *detailRequest.Name, existingDetail.ID); err != nil {
 			return nil, err
 		}
 		existingDetail.Name = *detailRequest.Name
+		existingDetail.UpdatedBy = model.ActorFromContext(ctx)
+
+		if err := s.UpdateTopicDetail(ctx, existingDetail); err != nil {
+			return nil, err
+		}
 	}
 
 	if detailRequest.Order != nil {
 		// Move topic detail to the new position and reorder all details accordingly
-		if err := s.MoveTopicDetailToPosition(existingDetail.ID, *detailRequest.Order); err != nil {
+		if err := s.MoveTopicDetailToPosition(ctx, existingDetail.ID, *detailRequest.Order); err != nil {
 			return nil, err
 		}
 
 		// Get the updated detail
-		updatedDetail, err := s.GetDetailByID(id)
+		updatedDetail, err := s.findDetail(ctx, id)
 		if err != nil {
 			return nil, err
 		}
 
 		return updatedDetail, nil
-	} else {
-		// No order update, just update other fields
-		if err := s.UpdateTopicDetail(existingDetail); err != nil {
-			return nil, err
-		}
 	}
 
-	existingDetail.UpdatedBy = "admin"
 	return existingDetail, nil
 }
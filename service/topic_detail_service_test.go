@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeTopicDetailRepository keeps details in memory, scoped to the organization in ctx
+type fakeTopicDetailRepository struct {
+	repository.TopicDetailRepository
+	details []model.TopicDetail
+}
+
+func (r *fakeTopicDetailRepository) FindByName(ctx context.Context, name string) (*model.TopicDetail, error) {
+	for _, detail := range r.details {
+		if detail.TenantID == model.TenantFromContext(ctx) && detail.Name == name {
+			return &detail, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestValidateTopicDetailNameHidesUnreadableTopics(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []model.TopicACLEntry
+		wantErr string
+	}{
+		{
+			name:    "detail under a topic open to the caller",
+			wantErr: "topic detail name already exists",
+		},
+		{
+			name:    "detail under a topic the caller can read",
+			entries: []model.TopicACLEntry{{ID: 1, TenantID: 1, TopicID: 1, SubjectType: model.TopicACLSubjectUser, SubjectID: 2, Access: model.TopicAccessRead}},
+			wantErr: "topic detail name already exists",
+		},
+		{
+			name:    "detail under a topic the caller cannot read",
+			entries: []model.TopicACLEntry{{ID: 1, TenantID: 1, TopicID: 1, SubjectType: model.TopicACLSubjectUser, SubjectID: 9, Access: model.TopicAccessAdmin}},
+			wantErr: "topic detail name is not available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topicRepo := &fakeTopicRepository{topics: []model.Topic{{ID: 1, TenantID: 1, Name: "ยา", Order: 1}}, nextID: 1}
+			detailRepo := &fakeTopicDetailRepository{details: []model.TopicDetail{{ID: 1, TenantID: 1, TopicID: 1, Name: "ยาแก้ปวด", Order: 1}}}
+			s := NewTopicDetailService(detailRepo, topicRepo, &fakeTopicACLRepository{entries: tt.entries})
+			ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: 2, Username: "john_doe", TenantID: 1})
+
+			err := s.ValidateTopicDetailName(ctx, "ยาแก้ปวด", 0)
+			checkError(t, "ValidateTopicDetailName()", err, tt.wantErr)
+
+			// A free name and the detail's own name stay available
+			checkError(t, "ValidateTopicDetailName() with a free name", s.ValidateTopicDetailName(ctx, "ยาแก้ไข้", 0), "")
+			checkError(t, "ValidateTopicDetailName() of the detail itself", s.ValidateTopicDetailName(ctx, "ยาแก้ปวด", 1), "")
+		})
+	}
+}
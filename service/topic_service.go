@@ -1,33 +1,61 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go-gin-gorm-backend/model"
 	"go-gin-gorm-backend/repository"
 	"go-gin-gorm-backend/utils"
+	"log"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 type TopicService interface {
-	CreateTopic(topic *model.Topic) error
-	CreateTopicWithValidation(topicRequest *model.CreateTopicRequest) (*model.Topic, error)
-	GetAllTopics() ([]model.Topic, error)
-	GetTopicByID(id string) (*model.Topic, error)
-	UpdateTopic(topic *model.Topic) error
-	UpdateTopicWithValidation(id string, topicRequest *model.UpdateTopicRequest) (*model.Topic, error)
-	DeleteTopic(id string) error
-	GetNextOrder() (int, error)
-	MoveTopicToPosition(topicID uint, newOrder int) error
-	ValidateTopicName(name string, excludeID uint) error
+	CreateTopic(ctx context.Context, topic *model.Topic) error
+	CreateTopicWithValidation(ctx context.Context, topicRequest *model.CreateTopicRequest) (*model.Topic, error)
+	GetAllTopics(ctx context.Context) ([]model.Topic, error)
+	GetTopicByID(ctx context.Context, id string) (*model.Topic, error)
+	UpdateTopic(ctx context.Context, topic *model.Topic) error
+	UpdateTopicWithValidation(ctx context.Context, id string, topicRequest *model.UpdateTopicRequest) (*model.Topic, error)
+	DeleteTopic(ctx context.Context, id string) error
+	GetNextOrder(ctx context.Context) (int, error)
+	MoveTopicToPosition(ctx context.Context, topicID uint, newOrder int) error
+	ValidateTopicName(ctx context.Context, name string, excludeID uint) error
+	GetTopicACL(ctx context.Context, id string) ([]model.TopicACLEntry, error)
+	SetTopicACLEntry(ctx context.Context, id string, entryRequest *model.SetTopicACLEntryRequest) ([]model.TopicACLEntry, error)
+	DeleteTopicACLEntry(ctx context.Context, id string, entryID uint) error
 }
 
 type topicService struct {
 	topicRepo repository.TopicRepository
+	teamRepo  repository.TeamRepository
+	aclRepo   repository.TopicACLRepository
+	userRepo  *repository.UserRepository
 }
 
-func NewTopicService(topicRepo repository.TopicRepository) TopicService {
-	return &topicService{topicRepo}
+func NewTopicService(topicRepo repository.TopicRepository, teamRepo repository.TeamRepository, aclRepo repository.TopicACLRepository, userRepo *repository.UserRepository) TopicService {
+	return &topicService{topicRepo, teamRepo, aclRepo, userRepo}
+}
+
+// resolveOwnerTeam validates a requested owning team. 0 means no owning team. Callers can only hand
+// a topic to a team of the organization they belong to, unless they hold topics:manage.
+func (s *topicService) resolveOwnerTeam(ctx context.Context, teamID uint) (*uint, error) {
+	if teamID == 0 {
+		return nil, nil
+	}
+
+	team, err := s.teamRepo.FindByID(ctx, teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+
+	principal, _ := model.PrincipalFromContext(ctx)
+	if !principal.InTeam(team.ID) && !principal.HasPermission(model.PermissionTopicsManage) {
+		return nil, errors.New("insufficient permissions")
+	}
+	return &team.ID, nil
 }
 
 func (s *topicService) handleDuplicateOrderError(err error) error {
@@ -62,8 +90,8 @@ func (s *topicService) handleDuplicateNameError(err error) error {
 	return err
 }
 
-func (s *topicService) GetNextOrder() (int, error) {
-	topics, err := s.topicRepo.FindAll()
+func (s *topicService) GetNextOrder(ctx context.Context) (int, error) {
+	topics, err := s.topicRepo.FindAll(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -71,8 +99,8 @@ func (s *topicService) GetNextOrder() (int, error) {
 	return utils.GetNextOrder(topics, func(t model.Topic) int { return t.Order }), nil
 }
 
-func (s *topicService) CreateTopic(topic *model.Topic) error {
-	err := s.topicRepo.Create(topic)
+func (s *topicService) CreateTopic(ctx context.Context, topic *model.Topic) error {
+	err := s.topicRepo.Create(ctx, topic)
 	if err != nil {
 		// Check for duplicate name error first
 		if s.handleDuplicateNameError(err).Error() == "topic name already exists" {
@@ -84,49 +112,92 @@ func (s *topicService) CreateTopic(topic *model.Topic) error {
 }
 
 // CreateTopicWithValidation handles all business logic for creating a topic
-func (s *topicService) CreateTopicWithValidation(topicRequest *model.CreateTopicRequest) (*model.Topic, error) {
+func (s *topicService) CreateTopicWithValidation(ctx context.Context, topicRequest *model.CreateTopicRequest) (*model.Topic, error) {
 	// Validate topic name uniqueness
-	if err := s.ValidateTopicName(topicRequest.Name, 0); err != nil {
+	if err := s.ValidateTopicName(ctx, topicRequest.Name, 0); err != nil {
 		return nil, err
 	}
 
+	var ownerTeamID *uint
+	if topicRequest.OwnerTeamID != nil {
+		teamID, err := s.resolveOwnerTeam(ctx, *topicRequest.OwnerTeamID)
+		if err != nil {
+			return nil, err
+		}
+		ownerTeamID = teamID
+	}
+
 	// Get the next order number
-	nextOrder, err := s.GetNextOrder()
+	nextOrder, err := s.GetNextOrder(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create topic with hardcoded values
+	// Stamp the authenticated user as creator
+	actor := model.ActorFromContext(ctx)
 	topic := &model.Topic{
-		Name:      topicRequest.Name,
-		Order:     nextOrder,
-		CreatedBy: "admin",
-		UpdatedBy: "admin",
+		Name:        topicRequest.Name,
+		Order:       nextOrder,
+		OwnerTeamID: ownerTeamID,
+		CreatedBy:   actor,
+		UpdatedBy:   actor,
 	}
 
 	// Create the topic
-	if err := s.CreateTopic(topic); err != nil {
+	if err := s.CreateTopic(ctx, topic); err != nil {
 		return nil, err
 	}
 
 	return topic, nil
 }
 
-func (s *topicService) GetAllTopics() ([]model.Topic, error) {
-	return s.topicRepo.FindAll()
+// GetAllTopics returns the topics the caller can read
+func (s *topicService) GetAllTopics(ctx context.Context) ([]model.Topic, error) {
+	topics, err := s.topicRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.aclRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entriesByTopic := groupTopicACLEntries(entries)
+
+	readable := make([]model.Topic, 0, len(topics))
+	for _, topic := range topics {
+		if model.TopicAccessAllows(topicAccess(ctx, &topic, entriesByTopic[topic.ID]), model.TopicAccessRead) {
+			readable = append(readable, topic)
+		}
+	}
+	return readable, nil
+}
+
+func (s *topicService) GetTopicByID(ctx context.Context, id string) (*model.Topic, error) {
+	topic, err := s.findTopic(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTopicAccess(ctx, s.aclRepo, topic, model.TopicAccessRead); err != nil {
+		return nil, err
+	}
+	return topic, nil
 }
 
-func (s *topicService) GetTopicByID(id string) (*model.Topic, error) {
+// findTopic looks up a topic without checking the access of the caller
+func (s *topicService) findTopic(ctx context.Context, id string) (*model.Topic, error) {
 	// Convert string to uint
 	idUint, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return nil, err
 	}
-	return s.topicRepo.FindByID(uint(idUint))
+	return s.topicRepo.FindByID(ctx, uint(idUint))
 }
 
-func (s *topicService) ValidateTopicName(name string, excludeID uint) error {
-	existingTopic, err := s.topicRepo.FindByName(name)
+// ValidateTopicName fails when another topic of the organization has the name. A topic the caller
+// cannot read is not named as such, the name is only reported as unavailable.
+func (s *topicService) ValidateTopicName(ctx context.Context, name string, excludeID uint) error {
+	existingTopic, err := s.topicRepo.FindByName(ctx, name)
 	if err != nil {
 		// If error is "record not found", then the name is available
 		if err.Error() == "record not found" {
@@ -140,26 +211,65 @@ func (s *topicService) ValidateTopicName(name string, excludeID uint) error {
 		return nil
 	}
 
+	if err := checkTopicAccess(ctx, s.aclRepo, existingTopic, model.TopicAccessRead); err != nil {
+		return errors.New("topic name is not available")
+	}
 	return errors.New("topic name already exists")
 }
 
-// MoveTopicToPosition moves a specific topic to a new position and reorders all topics accordingly
-func (s *topicService) MoveTopicToPosition(topicID uint, newOrder int) error {
-	topics, err := s.topicRepo.FindAll()
+// MoveTopicToPosition moves a specific topic to a new position and reorders the topics accordingly.
+// Only the topics the caller can write are renumbered, they trade the positions they already hold,
+// so topics the caller cannot write keep theirs.
+func (s *topicService) MoveTopicToPosition(ctx context.Context, topicID uint, newOrder int) error {
+	topics, err := s.topicRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := s.aclRepo.FindAll(ctx)
 	if err != nil {
 		return err
 	}
+	entriesByTopic := groupTopicACLEntries(entries)
+
+	// Topics come ordered by position
+	var writable []model.Topic
+	for _, topic := range topics {
+		if model.TopicAccessAllows(topicAccess(ctx, &topic, entriesByTopic[topic.ID]), model.TopicAccessWrite) {
+			writable = append(writable, topic)
+		}
+	}
+
+	index := slices.IndexFunc(writable, func(t model.Topic) bool { return t.ID == topicID })
+	if index < 0 {
+		return nil
+	}
+	target := writable[index]
+
+	positions := make([]int, len(writable))
+	for i, topic := range writable {
+		positions[i] = topic.Order
+	}
 
-	// Use the utility function to reorder items with target
-	reorderedTopics := utils.ReorderItemsWithTarget(topics,
-		func(t model.Topic) int { return t.Order },
-		func(t *model.Topic, order int) { t.Order = order },
-		func(t model.Topic) interface{} { return t.ID },
-		topicID, newOrder)
+	// The moved topic goes before the topics at or after the new position when moving up,
+	// and after the topics up to the new position when moving down
+	others := slices.Delete(slices.Clone(writable), index, index+1)
+	insertAt := 0
+	for _, topic := range others {
+		if topic.Order < newOrder || (newOrder > target.Order && topic.Order == newOrder) {
+			insertAt++
+		}
+	}
+	reorderedTopics := slices.Insert(others, insertAt, target)
 
-	// Update all topics in the database
-	for _, topic := range reorderedTopics {
-		if err := s.topicRepo.Update(&topic); err != nil {
+	// Update the topics whose position changed in the database
+	actor := model.ActorFromContext(ctx)
+	for i, topic := range reorderedTopics {
+		if topic.Order == positions[i] {
+			continue
+		}
+		topic.Order = positions[i]
+		topic.UpdatedBy = actor
+		if err := s.topicRepo.Update(ctx, &topic); err != nil {
 			return err
 		}
 	}
@@ -167,8 +277,8 @@ func (s *topicService) MoveTopicToPosition(topicID uint, newOrder int) error {
 	return nil
 }
 
-func (s *topicService) UpdateTopic(topic *model.Topic) error {
-	err := s.topicRepo.Update(topic)
+func (s *topicService) UpdateTopic(ctx context.Context, topic *model.Topic) error {
+	err := s.topicRepo.Update(ctx, topic)
 	if err != nil {
 		// Check for duplicate name error first
 		if s.handleDuplicateNameError(err).Error() == "topic name already exists" {
@@ -180,51 +290,198 @@ func (s *topicService) UpdateTopic(topic *model.Topic) error {
 }
 
 // UpdateTopicWithValidation handles all business logic for updating a topic
-func (s *topicService) UpdateTopicWithValidation(id string, topicRequest *model.UpdateTopicRequest) (*model.Topic, error) {
+func (s *topicService) UpdateTopicWithValidation(ctx context.Context, id string, topicRequest *model.UpdateTopicRequest) (*model.Topic, error) {
 	// Get existing topic to preserve fields
-	existingTopic, err := s.GetTopicByID(id)
+	existingTopic, err := s.findTopic(ctx, id)
 	if err != nil {
 		return nil, errors.New("topic not found")
 	}
 
+	// Handing the topic to another team takes admin access, everything else write access
+	required := model.TopicAccessWrite
+	if topicRequest.OwnerTeamID != nil {
+		required = model.TopicAccessAdmin
+	}
+	if err := checkTopicAccess(ctx, s.aclRepo, existingTopic, required); err != nil {
+		return nil, err
+	}
+
 	// Update only provided fields
-	if topicRequest.Name != nil {
-		// Validate topic name uniqueness (excluding current topic)
-		if err := s.ValidateTopicName(*topicRequest.Name, existingTopic.ID); err != nil {
+	if topicRequest.Name != nil || topicRequest.OwnerTeamID != nil {
+		if topicRequest.Name != nil {
+			// Validate topic name uniqueness (excluding current topic)
+			if err := s.ValidateTopicName(ctx, *topicRequest.Name, existingTopic.ID); err != nil {
+				return nil, err
+			}
+			existingTopic.Name = *topicRequest.Name
+		}
+		if topicRequest.OwnerTeamID != nil {
+			ownerTeamID, err := s.resolveOwnerTeam(ctx, *topicRequest.OwnerTeamID)
+			if err != nil {
+				return nil, err
+			}
+			existingTopic.OwnerTeamID = ownerTeamID
+		}
+		existingTopic.UpdatedBy = model.ActorFromContext(ctx)
+
+		if err := s.UpdateTopic(ctx, existingTopic); err != nil {
 			return nil, err
 		}
-		existingTopic.Name = *topicRequest.Name
 	}
 
 	if topicRequest.Order != nil {
 		// Move topic to the new position and reorder all topics accordingly
-		if err := s.MoveTopicToPosition(existingTopic.ID, *topicRequest.Order); err != nil {
+		if err := s.MoveTopicToPosition(ctx, existingTopic.ID, *topicRequest.Order); err != nil {
 			return nil, err
 		}
 
 		// Get the updated topic
-		updatedTopic, err := s.GetTopicByID(id)
+		updatedTopic, err := s.findTopic(ctx, id)
 		if err != nil {
 			return nil, err
 		}
 
 		return updatedTopic, nil
-	} else {
-		// No order update, just update other fields
-		if err := s.UpdateTopic(existingTopic); err != nil {
-			return nil, err
-		}
 	}
 
-	existingTopic.UpdatedBy = "admin"
 	return existingTopic, nil
 }
 
-func (s *topicService) DeleteTopic(id string) error {
+func (s *topicService) DeleteTopic(ctx context.Context, id string) error {
 	// Convert string to uint
 	idUint, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return err
 	}
-	return s.topicRepo.Delete(uint(idUint))
+
+	topic, err := s.topicRepo.FindByID(ctx, uint(idUint))
+	if err != nil {
+		return errors.New("topic not found")
+	}
+	if err := checkTopicAccess(ctx, s.aclRepo, topic, model.TopicAccessAdmin); err != nil {
+		return err
+	}
+
+	if err := s.topicRepo.Delete(ctx, uint(idUint)); err != nil {
+		return err
+	}
+
+	// The row is gone, so the actor is only kept in the log
+	log.Printf("Topic %d deleted by %s", idUint, model.ActorFromContext(ctx))
+	return nil
+}
+
+// GetTopicACL returns the access control list of a topic. Viewing it takes admin access to the topic.
+func (s *topicService) GetTopicACL(ctx context.Context, id string) ([]model.TopicACLEntry, error) {
+	topic, err := s.findTopic(ctx, id)
+	if err != nil {
+		return nil, errors.New("topic not found")
+	}
+	if err := checkTopicAccess(ctx, s.aclRepo, topic, model.TopicAccessAdmin); err != nil {
+		return nil, err
+	}
+	return s.aclRepo.FindByTopicID(ctx, topic.ID)
+}
+
+// SetTopicACLEntry grants a user or a team of the caller's organization access to a topic
+func (s *topicService) SetTopicACLEntry(ctx context.Context, id string, entryRequest *model.SetTopicACLEntryRequest) ([]model.TopicACLEntry, error) {
+	topic, err := s.findTopic(ctx, id)
+	if err != nil {
+		return nil, errors.New("topic not found")
+	}
+	entries, err := s.aclRepo.FindByTopicID(ctx, topic.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireTopicAccess(topicAccess(ctx, topic, entries), model.TopicAccessAdmin); err != nil {
+		return nil, err
+	}
+
+	switch entryRequest.SubjectType {
+	case model.TopicACLSubjectUser:
+		if !s.userRepo.ExistsInTenant(ctx, entryRequest.SubjectID) {
+			return nil, errors.New("user not found")
+		}
+	case model.TopicACLSubjectTeam:
+		if _, err := s.teamRepo.FindByID(ctx, entryRequest.SubjectID); err != nil {
+			return nil, errors.New("team not found")
+		}
+	}
+
+	actor := model.ActorFromContext(ctx)
+	entry := &model.TopicACLEntry{
+		TopicID:     topic.ID,
+		SubjectType: entryRequest.SubjectType,
+		SubjectID:   entryRequest.SubjectID,
+		Access:      entryRequest.Access,
+		CreatedBy:   actor,
+		UpdatedBy:   actor,
+	}
+
+	updated := make([]model.TopicACLEntry, 0, len(entries)+1)
+	for _, existing := range entries {
+		if existing.SubjectType != entry.SubjectType || existing.SubjectID != entry.SubjectID {
+			updated = append(updated, existing)
+		}
+	}
+	updated = append(updated, *entry)
+	if err := checkACLLockout(ctx, topic, updated); err != nil {
+		return nil, err
+	}
+
+	if err := s.aclRepo.Save(ctx, entry); err != nil {
+		return nil, err
+	}
+	return s.aclRepo.FindByTopicID(ctx, topic.ID)
+}
+
+// DeleteTopicACLEntry removes an entry from the access control list of a topic.
+// Removing the last entry opens the topic to everyone of the organization again.
+func (s *topicService) DeleteTopicACLEntry(ctx context.Context, id string, entryID uint) error {
+	topic, err := s.findTopic(ctx, id)
+	if err != nil {
+		return errors.New("topic not found")
+	}
+	entries, err := s.aclRepo.FindByTopicID(ctx, topic.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireTopicAccess(topicAccess(ctx, topic, entries), model.TopicAccessAdmin); err != nil {
+		return err
+	}
+
+	updated := make([]model.TopicACLEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.ID != entryID {
+			updated = append(updated, entry)
+		}
+	}
+	if len(updated) == len(entries) {
+		return errors.New("topic acl entry not found")
+	}
+	if err := checkACLLockout(ctx, topic, updated); err != nil {
+		return err
+	}
+
+	deleted, err := s.aclRepo.Delete(ctx, topic.ID, entryID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("topic acl entry not found")
+	}
+
+	// The row is gone, so the actor is only kept in the log
+	log.Printf("Topic %d ACL entry %d deleted by %s", topic.ID, entryID, model.ActorFromContext(ctx))
+	return nil
+}
+
+// checkACLLockout keeps the caller from losing admin access to the topic with the updated entries.
+// The first entry closes an open topic, so it must not leave out the caller.
+func checkACLLockout(ctx context.Context, topic *model.Topic, updated []model.TopicACLEntry) error {
+	if !model.TopicAccessAllows(topicAccess(ctx, topic, updated), model.TopicAccessAdmin) {
+		return errors.New("cannot remove your own admin access to the topic")
+	}
+	return nil
 }
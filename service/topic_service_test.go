@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go-gin-gorm-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// fakeTopicRepository keeps topics in memory and, like the database repository, only sees the
+// topics of the organization in ctx
+type fakeTopicRepository struct {
+	topics []model.Topic
+	nextID uint
+}
+
+func (r *fakeTopicRepository) Create(ctx context.Context, topic *model.Topic) error {
+	r.nextID++
+	topic.ID = r.nextID
+	topic.TenantID = model.TenantFromContext(ctx)
+	r.topics = append(r.topics, *topic)
+	return nil
+}
+
+func (r *fakeTopicRepository) FindAll(ctx context.Context) ([]model.Topic, error) {
+	var topics []model.Topic
+	for _, topic := range r.topics {
+		if topic.TenantID == model.TenantFromContext(ctx) {
+			topics = append(topics, topic)
+		}
+	}
+	slices.SortFunc(topics, func(a, b model.Topic) int { return a.Order - b.Order })
+	return topics, nil
+}
+
+func (r *fakeTopicRepository) FindByID(ctx context.Context, id uint) (*model.Topic, error) {
+	return r.find(ctx, func(t model.Topic) bool { return t.ID == id })
+}
+
+func (r *fakeTopicRepository) FindByName(ctx context.Context, name string) (*model.Topic, error) {
+	return r.find(ctx, func(t model.Topic) bool { return t.Name == name })
+}
+
+func (r *fakeTopicRepository) find(ctx context.Context, match func(model.Topic) bool) (*model.Topic, error) {
+	for _, topic := range r.topics {
+		if topic.TenantID == model.TenantFromContext(ctx) && match(topic) {
+			return &topic, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTopicRepository) Update(ctx context.Context, topic *model.Topic) error {
+	for i := range r.topics {
+		if r.topics[i].ID == topic.ID && r.topics[i].TenantID == model.TenantFromContext(ctx) {
+			r.topics[i] = *topic
+		}
+	}
+	return nil
+}
+
+func (r *fakeTopicRepository) Delete(ctx context.Context, id uint) error {
+	r.topics = slices.DeleteFunc(r.topics, func(t model.Topic) bool {
+		return t.ID == id && t.TenantID == model.TenantFromContext(ctx)
+	})
+	return nil
+}
+
+// fakeTopicACLRepository keeps ACL entries in memory, scoped to the organization in ctx
+type fakeTopicACLRepository struct {
+	entries []model.TopicACLEntry
+}
+
+func (r *fakeTopicACLRepository) FindAll(ctx context.Context) ([]model.TopicACLEntry, error) {
+	var entries []model.TopicACLEntry
+	for _, entry := range r.entries {
+		if entry.TenantID == model.TenantFromContext(ctx) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeTopicACLRepository) FindByTopicID(ctx context.Context, topicID uint) ([]model.TopicACLEntry, error) {
+	var entries []model.TopicACLEntry
+	for _, entry := range r.entries {
+		if entry.TenantID == model.TenantFromContext(ctx) && entry.TopicID == topicID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeTopicACLRepository) Save(ctx context.Context, entry *model.TopicACLEntry) error {
+	entry.TenantID = model.TenantFromContext(ctx)
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *fakeTopicACLRepository) Delete(ctx context.Context, topicID, id uint) (bool, error) {
+	before := len(r.entries)
+	r.entries = slices.DeleteFunc(r.entries, func(e model.TopicACLEntry) bool {
+		return e.ID == id && e.TopicID == topicID && e.TenantID == model.TenantFromContext(ctx)
+	})
+	return len(r.entries) < before, nil
+}
+
+func TestTopicActorRecording(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal model.Principal
+		want      string
+	}{
+		{
+			name:      "user",
+			principal: model.Principal{UserID: 2, Username: "john_doe", TenantID: 1},
+			want:      "john_doe",
+		},
+		{
+			name:      "admin impersonating a user",
+			principal: model.Principal{UserID: 2, Username: "john_doe", TenantID: 1, ActorUsername: "admin"},
+			want:      "john_doe (via admin)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topicRepo := &fakeTopicRepository{
+				topics: []model.Topic{{ID: 1, TenantID: 1, Name: "ยา", Order: 1, CreatedBy: "admin", UpdatedBy: "admin"}},
+				nextID: 1,
+			}
+			s := NewTopicService(topicRepo, nil, &fakeTopicACLRepository{}, nil)
+			ctx := model.WithPrincipal(context.Background(), tt.principal)
+
+			created, err := s.CreateTopicWithValidation(ctx, &model.CreateTopicRequest{Name: "วิตามิน"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.CreatedBy != tt.want || created.UpdatedBy != tt.want {
+				t.Errorf("created by %q, updated by %q, want %q", created.CreatedBy, created.UpdatedBy, tt.want)
+			}
+
+			name := "ยาสามัญ"
+			updated, err := s.UpdateTopicWithValidation(ctx, "1", &model.UpdateTopicRequest{Name: &name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.CreatedBy != "admin" {
+				t.Errorf("creator changed to %q on update", updated.CreatedBy)
+			}
+			if updated.UpdatedBy != tt.want {
+				t.Errorf("updated by %q, want %q", updated.UpdatedBy, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopicCrossTenantReads(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal model.Principal
+		wantFound bool
+	}{
+		{
+			name:      "same organization",
+			principal: model.Principal{UserID: 2, Username: "john_doe", TenantID: 1},
+			wantFound: true,
+		},
+		{
+			name:      "other organization",
+			principal: model.Principal{UserID: 3, Username: "jane_roe", TenantID: 2},
+		},
+		{
+			name:      "other organization holding topics:manage",
+			principal: model.Principal{UserID: 3, Username: "jane_roe", TenantID: 2, Permissions: []string{model.PermissionTopicsManage}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topicRepo := &fakeTopicRepository{
+				topics: []model.Topic{{ID: 1, TenantID: 1, Name: "ยา", Order: 1, CreatedBy: "admin"}},
+				nextID: 1,
+			}
+			s := NewTopicService(topicRepo, nil, &fakeTopicACLRepository{}, nil)
+			ctx := model.WithPrincipal(context.Background(), tt.principal)
+
+			_, err := s.GetTopicByID(ctx, "1")
+			if tt.wantFound && err != nil {
+				t.Errorf("GetTopicByID() error = %v", err)
+			}
+			if !tt.wantFound && err == nil {
+				t.Error("GetTopicByID() found a topic of another organization")
+			}
+
+			topics, err := s.GetAllTopics(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found := len(topics) == 1; found != tt.wantFound {
+				t.Errorf("GetAllTopics() = %v, want found %v", topics, tt.wantFound)
+			}
+
+			// The name of a topic of another organization is free
+			err = s.ValidateTopicName(ctx, "ยา", 0)
+			if tt.wantFound && err == nil {
+				t.Error("ValidateTopicName() accepted a taken name")
+			}
+			if !tt.wantFound && err != nil {
+				t.Errorf("ValidateTopicName() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTopicACLDeny(t *testing.T) {
+	otherTeam := uint(5)
+
+	tests := []struct {
+		name        string
+		topic       model.Topic
+		entries     []model.TopicACLEntry
+		wantReadErr string
+		wantEditErr string
+		wantNameErr string
+	}{
+		{
+			name:        "no entry for the caller",
+			topic:       model.Topic{ID: 1, TenantID: 1, Name: "ยา", Order: 1},
+			entries:     []model.TopicACLEntry{{ID: 1, TenantID: 1, TopicID: 1, SubjectType: model.TopicACLSubjectUser, SubjectID: 9, Access: model.TopicAccessAdmin}},
+			wantReadErr: "topic not found",
+			wantEditErr: "topic not found",
+			wantNameErr: "topic name is not available",
+		},
+		{
+			name:        "read access",
+			topic:       model.Topic{ID: 1, TenantID: 1, Name: "ยา", Order: 1},
+			entries:     []model.TopicACLEntry{{ID: 1, TenantID: 1, TopicID: 1, SubjectType: model.TopicACLSubjectUser, SubjectID: 2, Access: model.TopicAccessRead}},
+			wantEditErr: "insufficient permissions",
+			wantNameErr: "topic name already exists",
+		},
+		{
+			name:        "owned by another team without entries",
+			topic:       model.Topic{ID: 1, TenantID: 1, Name: "ยา", Order: 1, OwnerTeamID: &otherTeam},
+			wantEditErr: "insufficient permissions",
+			wantNameErr: "topic name already exists",
+		},
+		{
+			name:        "write access",
+			topic:       model.Topic{ID: 1, TenantID: 1, Name: "ยา", Order: 1},
+			entries:     []model.TopicACLEntry{{ID: 1, TenantID: 1, TopicID: 1, SubjectType: model.TopicACLSubjectUser, SubjectID: 2, Access: model.TopicAccessWrite}},
+			wantNameErr: "topic name already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topicRepo := &fakeTopicRepository{topics: []model.Topic{tt.topic}, nextID: 1}
+			s := NewTopicService(topicRepo, nil, &fakeTopicACLRepository{entries: tt.entries}, nil)
+			ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: 2, Username: "john_doe", TenantID: 1})
+
+			_, err := s.GetTopicByID(ctx, "1")
+			checkError(t, "GetTopicByID()", err, tt.wantReadErr)
+
+			topics, err := s.GetAllTopics(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if listed := len(topics) == 1; listed != (tt.wantReadErr == "") {
+				t.Errorf("GetAllTopics() = %v, want listed %v", topics, tt.wantReadErr == "")
+			}
+
+			err = s.ValidateTopicName(ctx, tt.topic.Name, 0)
+			checkError(t, "ValidateTopicName()", err, tt.wantNameErr)
+
+			name := "ยาสามัญ"
+			_, err = s.UpdateTopicWithValidation(ctx, "1", &model.UpdateTopicRequest{Name: &name})
+			checkError(t, "UpdateTopicWithValidation()", err, tt.wantEditErr)
+			if tt.wantEditErr != "" && topicRepo.topics[0].Name != tt.topic.Name {
+				t.Errorf("topic renamed to %q without write access", topicRepo.topics[0].Name)
+			}
+		})
+	}
+}
+
+func TestMoveTopicSkipsUnwritableTopics(t *testing.T) {
+	topicRepo := &fakeTopicRepository{
+		topics: []model.Topic{
+			{ID: 1, TenantID: 1, Name: "open", Order: 1, UpdatedBy: "admin"},
+			{ID: 2, TenantID: 1, Name: "hidden", Order: 2, UpdatedBy: "admin"},
+			{ID: 3, TenantID: 1, Name: "read only", Order: 3, UpdatedBy: "admin"},
+			{ID: 4, TenantID: 1, Name: "writable", Order: 4, UpdatedBy: "admin"},
+		},
+		nextID: 4,
+	}
+	aclRepo := &fakeTopicACLRepository{entries: []model.TopicACLEntry{
+		{ID: 1, TenantID: 1, TopicID: 2, SubjectType: model.TopicACLSubjectUser, SubjectID: 9, Access: model.TopicAccessAdmin},
+		{ID: 2, TenantID: 1, TopicID: 3, SubjectType: model.TopicACLSubjectUser, SubjectID: 2, Access: model.TopicAccessRead},
+		{ID: 3, TenantID: 1, TopicID: 4, SubjectType: model.TopicACLSubjectUser, SubjectID: 2, Access: model.TopicAccessWrite},
+	}}
+	s := NewTopicService(topicRepo, nil, aclRepo, nil)
+	ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: 2, Username: "john_doe", TenantID: 1})
+
+	tests := []struct {
+		name      string
+		topicID   uint
+		newOrder  int
+		wantOrder map[uint]int
+	}{
+		{
+			name:      "writable topic moved to the top trades places with the other writable topic",
+			topicID:   4,
+			newOrder:  1,
+			wantOrder: map[uint]int{1: 4, 2: 2, 3: 3, 4: 1},
+		},
+		{
+			name:      "read-only topic is not moved",
+			topicID:   3,
+			newOrder:  1,
+			wantOrder: map[uint]int{1: 4, 2: 2, 3: 3, 4: 1},
+		},
+		{
+			name:      "hidden topic is not moved",
+			topicID:   2,
+			newOrder:  4,
+			wantOrder: map[uint]int{1: 4, 2: 2, 3: 3, 4: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.MoveTopicToPosition(ctx, tt.topicID, tt.newOrder); err != nil {
+				t.Fatal(err)
+			}
+			for _, topic := range topicRepo.topics {
+				if topic.Order != tt.wantOrder[topic.ID] {
+					t.Errorf("topic %d at %d, want %d", topic.ID, topic.Order, tt.wantOrder[topic.ID])
+				}
+			}
+		})
+	}
+
+	// Topics the caller cannot write were never touched
+	for _, topic := range topicRepo.topics[1:3] {
+		if topic.UpdatedBy != "admin" {
+			t.Errorf("topic %d updated by %q", topic.ID, topic.UpdatedBy)
+		}
+	}
+}
+
+func checkError(t *testing.T, call string, err error, want string) {
+	t.Helper()
+	if want == "" && err != nil {
+		t.Errorf("%s error = %v, want none", call, err)
+	}
+	if want != "" && (err == nil || err.Error() != want) {
+		t.Errorf("%s error = %v, want %q", call, err, want)
+	}
+}
+
+func TestTopicACLFirstEntry(t *testing.T) {
+	pharmacy := uint(5)
+
+	tests := []struct {
+		name       string
+		topic      model.Topic
+		principal  model.Principal
+		wantErr    string
+		wantAccess string // the caller's access to the topic before any entry exists
+	}{
+		{
+			name:       "writer closing an open topic",
+			topic:      model.Topic{ID: 1, TenantID: 1, Name: "ยา", Order: 1},
+			principal:  model.Principal{UserID: 2, Username: "john_doe", TenantID: 1, TeamIDs: []uint{pharmacy}},
+			wantErr:    "insufficient permissions",
+			wantAccess: model.TopicAccessWrite,
+		},
+		{
+			name:       "topics:manage closing an open topic",
+			topic:      model.Topic{ID: 1, TenantID: 1, Name: "ยา", Order: 1},
+			principal:  model.Principal{UserID: 1, Username: "admin", TenantID: 1, Permissions: []string{model.PermissionTopicsManage}},
+			wantAccess: model.TopicAccessAdmin,
+		},
+		{
+			name:       "member of the owning team",
+			topic:      model.Topic{ID: 1, TenantID: 1, Name: "ยา", Order: 1, OwnerTeamID: &pharmacy},
+			principal:  model.Principal{UserID: 2, Username: "john_doe", TenantID: 1, TeamIDs: []uint{pharmacy}},
+			wantAccess: model.TopicAccessAdmin,
+		},
+		{
+			name:       "writer outside the owning team",
+			topic:      model.Topic{ID: 1, TenantID: 1, Name: "ยา", Order: 1, OwnerTeamID: &pharmacy},
+			principal:  model.Principal{UserID: 3, Username: "jane_roe", TenantID: 1},
+			wantErr:    "insufficient permissions",
+			wantAccess: model.TopicAccessRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aclRepo := &fakeTopicACLRepository{}
+			teamRepo := &fakeTeamRepository{teams: []model.Team{{ID: pharmacy, TenantID: 1, Name: "pharmacy"}}}
+			s := NewTopicService(&fakeTopicRepository{topics: []model.Topic{tt.topic}, nextID: 1}, teamRepo, aclRepo, nil)
+			ctx := model.WithPrincipal(context.Background(), tt.principal)
+
+			if got := topicAccess(ctx, &tt.topic, nil); got != tt.wantAccess {
+				t.Errorf("topicAccess() = %q, want %q", got, tt.wantAccess)
+			}
+
+			_, err := s.SetTopicACLEntry(ctx, "1", &model.SetTopicACLEntryRequest{
+				SubjectType: model.TopicACLSubjectTeam,
+				SubjectID:   pharmacy,
+				Access:      model.TopicAccessAdmin,
+			})
+			checkError(t, "SetTopicACLEntry()", err, tt.wantErr)
+			if tt.wantErr != "" && len(aclRepo.entries) > 0 {
+				t.Errorf("entries saved without admin access: %v", aclRepo.entries)
+			}
+
+			err = s.DeleteTopic(ctx, "1")
+			checkError(t, "DeleteTopic()", err, tt.wantErr)
+		})
+	}
+}
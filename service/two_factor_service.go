@@ -0,0 +1,289 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeAlphabet leaves out characters that are easily confused, such as 0/o and 1/l/i
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+type TwoFactorService struct {
+	userRepo        *repository.UserRepository
+	recoveryRepo    repository.RecoveryCodeRepository
+	tokenService    *TokenService
+	throttleService *LoginThrottleService
+	securityEvents  *SecurityEventService
+	cfg             *config.TwoFactorConfig
+}
+
+func NewTwoFactorService(userRepo *repository.UserRepository, recoveryRepo repository.RecoveryCodeRepository, tokenService *TokenService, throttleService *LoginThrottleService, securityEvents *SecurityEventService, cfg *config.TwoFactorConfig) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:        userRepo,
+		recoveryRepo:    recoveryRepo,
+		tokenService:    tokenService,
+		throttleService: throttleService,
+		securityEvents:  securityEvents,
+		cfg:             cfg,
+	}
+}
+
+// IssueChallenge returns the first step of a two-step login: a short-lived challenge token
+// that is exchanged together with a code at VerifyLogin
+func (s *TwoFactorService) IssueChallenge(user *model.User) (*model.LoginResponse, error) {
+	challengeToken, _, err := config.GenerateChallengeToken(user, s.cfg.ChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LoginResponse{TwoFactorRequired: true, ChallengeToken: challengeToken}, nil
+}
+
+// VerifyLogin completes a two-step login with a TOTP code or a recovery code.
+// Failed codes count towards the same lockout as failed passwords.
+func (s *TwoFactorService) VerifyLogin(req *model.TwoFactorVerifyRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	claims, err := s.tokenService.ValidateChallengeToken(req.ChallengeToken)
+	if err != nil {
+		s.securityEvents.Record(model.SecurityEventLoginTwoFactor, nil, "", client, model.SecurityReasonInvalidToken)
+		return nil, errors.New("invalid or expired challenge")
+	}
+
+	if err := s.throttleService.CheckLogin(claims.Username, client.IPAddress); err != nil {
+		s.securityEvents.Record(model.SecurityEventLoginTwoFactor, nil, claims.Username, client, model.SecurityReasonLockedOut)
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(claims.UserID)
+	if err != nil || !user.TwoFactorEnabled {
+		s.securityEvents.Record(model.SecurityEventLoginTwoFactor, nil, claims.Username, client, model.SecurityReasonInvalidToken)
+		return nil, errors.New("invalid or expired challenge")
+	}
+	if !user.IsActive {
+		s.securityEvents.Record(model.SecurityEventLoginTwoFactor, user, user.Username, client, model.SecurityReasonInactiveAccount)
+		return nil, errors.New("account is deactivated")
+	}
+
+	valid, err := s.verifyCode(user, req.Code, true)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		s.securityEvents.Record(model.SecurityEventLoginTwoFactor, user, user.Username, client, model.SecurityReasonInvalidCode)
+		if err := s.throttleService.RecordFailure(user.Username, client.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	if err := s.throttleService.RecordSuccess(user.Username); err != nil {
+		return nil, err
+	}
+
+	// A challenge can only be exchanged once
+	if err := s.tokenService.RevokeToken(claims); err != nil {
+		return nil, err
+	}
+
+	s.securityEvents.Record(model.SecurityEventLoginTwoFactor, user, user.Username, client, "")
+	return s.tokenService.IssueTokens(user, client)
+}
+
+// BeginEnrollment generates a new TOTP secret for the user. It is not enforced until confirmed with EnableTwoFactor.
+func (s *TwoFactorService) BeginEnrollment(userID uint) (*model.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorSecret = secret
+	if err := s.userRepo.UpdateUser(user, "two_factor_secret"); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.cfg.Issuer, user.Username, secret),
+	}, nil
+}
+
+// EnableTwoFactor confirms the enrollment with a code from the authenticator app and returns the recovery codes
+func (s *TwoFactorService) EnableTwoFactor(userID uint, req *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, errors.New("two-factor enrollment has not been started")
+	}
+
+	step, ok := utils.ValidateTOTP(user.TwoFactorSecret, normalizeCode(req.Code), time.Now())
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	user.TwoFactorEnabled = true
+	user.TwoFactorLastStep = step
+	if err := s.userRepo.UpdateUser(user, "two_factor_enabled", "two_factor_last_step"); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking the password and a code.
+// It is refused while a role of the user requires two-factor authentication.
+func (s *TwoFactorService) DisableTwoFactor(userID uint, req *model.DisableTwoFactorRequest) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.TwoFactorEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if user.RequiresTwoFactor() {
+		return errors.New("two-factor authentication is required for your role")
+	}
+	if !config.CheckPassword(req.Password, user.Password) {
+		return errors.New("current password is incorrect")
+	}
+
+	valid, err := s.verifyCode(user, req.Code, true)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("invalid two-factor code")
+	}
+
+	return s.clearTwoFactor(user)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user after checking a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, req *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	valid, err := s.verifyCode(user, req.Code, false)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// ResetTwoFactor removes the enrollment of a user who lost their authenticator and revokes their sessions.
+// Users whose role requires two-factor authentication have to enroll again on their next login.
+func (s *TwoFactorService) ResetTwoFactor(userID uint) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := s.clearTwoFactor(user); err != nil {
+		return err
+	}
+	return s.tokenService.RevokeAllUserSessions(user.ID)
+}
+
+func (s *TwoFactorService) clearTwoFactor(user *model.User) error {
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = ""
+	if err := s.userRepo.UpdateUser(user, "two_factor_enabled", "two_factor_secret"); err != nil {
+		return err
+	}
+	return s.recoveryRepo.DeleteByUserID(user.ID)
+}
+
+// verifyCode checks a TOTP code, or a recovery code when allowed. Every code is accepted only once.
+func (s *TwoFactorService) verifyCode(user *model.User, code string, allowRecovery bool) (bool, error) {
+	code = normalizeCode(code)
+
+	if len(code) == utils.TOTPDigits && strings.Trim(code, "0123456789") == "" {
+		step, ok := utils.ValidateTOTP(user.TwoFactorSecret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.userRepo.AdvanceTwoFactorStep(user.ID, step)
+	}
+
+	if !allowRecovery {
+		return false, nil
+	}
+
+	recoveryCode, err := s.recoveryRepo.FindUnused(user.ID, utils.HashToken(code))
+	if err != nil {
+		return false, nil
+	}
+	return s.recoveryRepo.MarkUsed(recoveryCode.ID, time.Now())
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(userID uint) (*model.RecoveryCodesResponse, error) {
+	plainCodes := make([]string, 0, recoveryCodeCount)
+	codes := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		plainCodes = append(plainCodes, code)
+		codes = append(codes, model.RecoveryCode{UserID: userID, CodeHash: utils.HashToken(normalizeCode(code))})
+	}
+
+	if err := s.recoveryRepo.ReplaceForUser(userID, codes); err != nil {
+		return nil, err
+	}
+	return &model.RecoveryCodesResponse{RecoveryCodes: plainCodes}, nil
+}
+
+// generateRecoveryCode returns a code formatted as "xxxx-xxxx"
+func generateRecoveryCode() (string, error) {
+	// Reject bytes beyond the largest multiple of the alphabet size so every character is equally likely
+	limit := byte(256 - 256%len(recoveryCodeAlphabet))
+	code := make([]byte, 0, 8)
+	buf := make([]byte, 16)
+	for len(code) < 8 {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if b < limit && len(code) < 8 {
+				code = append(code, recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+			}
+		}
+	}
+	return string(code[:4]) + "-" + string(code[4:]), nil
+}
+
+// normalizeCode strips the separators users type or copy along with a code
+func normalizeCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+func TestVerifyCodeRejectsReplays(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := utils.TOTPStep(time.Now())
+
+	tests := []struct {
+		name         string
+		lastStep     int64 // last step accepted before
+		codeStep     int64
+		wantValid    bool
+		wantLastStep int64
+	}{
+		{name: "fresh code", lastStep: step - 5, codeStep: step, wantValid: true, wantLastStep: step},
+		{name: "code of the previous step within the skew", lastStep: step - 5, codeStep: step - 1, wantValid: true, wantLastStep: step - 1},
+		{name: "same code again", lastStep: step, codeStep: step, wantLastStep: step},
+		{name: "older code after a newer one", lastStep: step, codeStep: step - 1, wantLastStep: step},
+		{name: "code outside the skew", lastStep: step - 5, codeStep: step + 10, wantLastStep: step - 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+
+			// Play the database: the conditional update only matches while the new step is later than the stored one
+			lastStep := tt.lastStep
+			onUpdate(t, db, func(tx *gorm.DB) {
+				values, ok := tx.Statement.Dest.(map[string]interface{})
+				if !ok {
+					return
+				}
+				if newStep, ok := values["two_factor_last_step"].(int64); ok && newStep > lastStep {
+					lastStep = newStep
+					tx.RowsAffected = 1
+				}
+			})
+
+			code, err := utils.TOTPCode(secret, tt.codeStep)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := NewTwoFactorService(repository.NewUserRepository(db), nil, nil, nil, nil, nil)
+			user := &model.User{ID: 2, TwoFactorEnabled: true, TwoFactorSecret: secret, TwoFactorLastStep: tt.lastStep}
+
+			valid, err := s.verifyCode(user, code, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if valid != tt.wantValid {
+				t.Errorf("verifyCode() = %v, want %v", valid, tt.wantValid)
+			}
+			if lastStep != tt.wantLastStep {
+				t.Errorf("last accepted step = %d, want %d", lastStep, tt.wantLastStep)
+			}
+		})
+	}
+}
+
+func TestTwoFactorSetupPending(t *testing.T) {
+	tests := []struct {
+		name        string
+		required    bool
+		enabled     bool
+		wantPending bool
+	}{
+		{name: "role requires 2FA, not enrolled", required: true, wantPending: true},
+		{name: "role requires 2FA, enrolled since the token was issued", required: true, enabled: true},
+		{name: "role no longer requires 2FA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			onQuery(t, db, func(tx *gorm.DB) {
+				if user, ok := tx.Statement.Dest.(*model.User); ok {
+					user.ID = 2
+					user.TwoFactorEnabled = tt.enabled
+					user.Roles = []model.Role{{ID: 1, Name: "admin", RequireTwoFactor: tt.required}}
+				}
+			})
+
+			tokenService := NewTokenService(repository.NewUserRepository(db), nil, nil, nil, nil)
+			pending, err := tokenService.TwoFactorSetupPending(2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pending != tt.wantPending {
+				t.Errorf("TwoFactorSetupPending() = %v, want %v", pending, tt.wantPending)
+			}
+		})
+	}
+}
@@ -1,49 +1,426 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log"
+	"slices"
+	"strings"
+
 	"go-gin-gorm-backend/config"
 	"go-gin-gorm-backend/model"
 	"go-gin-gorm-backend/repository"
+	"go-gin-gorm-backend/utils"
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo         *repository.UserRepository
+	roleRepo         repository.RoleRepository
+	organizationRepo repository.OrganizationRepository
+	tokenService     *TokenService
+	throttleService  *LoginThrottleService
+	twoFactorService *TwoFactorService
+	securityEvents   *SecurityEventService
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo *repository.UserRepository, roleRepo repository.RoleRepository, organizationRepo repository.OrganizationRepository, tokenService *TokenService, throttleService *LoginThrottleService, twoFactorService *TwoFactorService, securityEvents *SecurityEventService) *UserService {
+	return &UserService{
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		organizationRepo: organizationRepo,
+		tokenService:     tokenService,
+		throttleService:  throttleService,
+		twoFactorService: twoFactorService,
+		securityEvents:   securityEvents,
+	}
 }
 
-// LoginUser authenticates a user and returns tokens
-func (s *UserService) LoginUser(req *model.LoginRequest) (*model.LoginResponse, error) {
+// LoginUser authenticates a user and returns tokens, or a challenge token when two-factor authentication is enabled.
+// Failed attempts are counted per username and per client IP and lead to a temporary lockout.
+func (s *UserService) LoginUser(req *model.LoginRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	// Reject locked usernames and IPs before spending a password hash comparison
+	if err := s.throttleService.CheckLogin(req.Username, client.IPAddress); err != nil {
+		s.securityEvents.Record(model.SecurityEventLogin, nil, req.Username, client, model.SecurityReasonLockedOut)
+		return nil, err
+	}
+
 	// Get user by username
+	// Unknown usernames and service accounts still pay for a password check, so the response time does not reveal them
 	user, err := s.userRepo.GetUserByUsername(req.Username)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		config.SimulatePasswordCheck(req.Password)
+		return nil, s.loginFailed(req.Username, nil, client, model.SecurityReasonUnknownUser)
 	}
 
-	// Check if user is active
-	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+	// Service accounts authenticate with API keys only
+	if user.IsServiceAccount {
+		config.SimulatePasswordCheck(req.Password)
+		return nil, s.loginFailed(req.Username, user, client, model.SecurityReasonServiceAccount)
 	}
 
 	// Check password
 	if !config.CheckPassword(req.Password, user.Password) {
-		return nil, errors.New("invalid credentials")
+		return nil, s.loginFailed(req.Username, user, client, model.SecurityReasonInvalidPassword)
+	}
+
+	// Only tell why an account cannot log in to someone who knows its password
+	if !user.IsActive {
+		s.securityEvents.Record(model.SecurityEventLogin, user, req.Username, client, model.SecurityReasonInactiveAccount)
+		return nil, inactiveAccountError(user)
+	}
+
+	if err := s.throttleService.RecordSuccess(user.Username); err != nil {
+		return nil, err
+	}
+
+	if config.PasswordNeedsRehash(user.Password) {
+		s.rehashPassword(user, req.Password)
+	}
+
+	// The password alone is not enough, a code has to be verified first
+	if user.TwoFactorEnabled {
+		s.securityEvents.Record(model.SecurityEventLogin, user, req.Username, client, model.SecurityReasonTwoFactorPending)
+		return s.twoFactorService.IssueChallenge(user)
 	}
 
 	// Generate tokens
-	token, err := config.GenerateToken(user)
+	s.securityEvents.Record(model.SecurityEventLogin, user, req.Username, client, "")
+	return s.tokenService.IssueTokens(user, client)
+}
+
+// loginFailed records a failed login attempt and returns the error reported to the client.
+// The reason is only kept in the security event log, the client always gets the same error.
+func (s *UserService) loginFailed(username string, user *model.User, client model.ClientInfo, reason string) error {
+	s.securityEvents.Record(model.SecurityEventLogin, user, username, client, reason)
+	if err := s.throttleService.RecordFailure(username, client.IPAddress); err != nil {
+		return err
+	}
+	return errors.New("invalid credentials")
+}
+
+// rehashPassword upgrades the stored hash of a verified password to the configured algorithm and parameters.
+// A failure is only logged, the old hash keeps working.
+func (s *UserService) rehashPassword(user *model.User, password string) {
+	hashedPassword, err := config.HashPassword(password)
+	if err != nil {
+		log.Printf("Error re-hashing password of user %d: %v", user.ID, err)
+		return
+	}
+	if err := s.userRepo.UpdatePasswordHash(user.ID, user.Password, hashedPassword); err != nil {
+		log.Printf("Error re-hashing password of user %d: %v", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+// ChangePassword changes the password of the authenticated user.
+// All existing sessions are revoked and a fresh token pair is returned.
+func (s *UserService) ChangePassword(userID uint, req *model.ChangePasswordRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !config.CheckPassword(req.CurrentPassword, user.Password) {
+		s.securityEvents.Record(model.SecurityEventPasswordChange, user, user.Username, client, model.SecurityReasonInvalidPassword)
+		return nil, errors.New("current password is incorrect")
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return nil, errors.New("new password must be different from the current password")
+	}
+
+	if err := config.ValidatePassword(req.NewPassword, user.Username); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := config.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Password = hashedPassword
+	user.MustChangePassword = false
+	if err := s.userRepo.UpdateUser(user, "password", "must_change_password"); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenService.RevokeAllUserSessions(user.ID); err != nil {
+		return nil, err
+	}
+
+	s.securityEvents.Record(model.SecurityEventPasswordChange, user, user.Username, client, "")
+	return s.tokenService.IssueTokens(user, client)
+}
+
+// ListUsers returns all users of the caller's organization that have not been deleted
+func (s *UserService) ListUsers(ctx context.Context) ([]model.UserResponse, error) {
+	users, err := s.userRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, user.ToUserResponse())
+	}
+	return responses, nil
+}
+
+// GetUser returns a single user
+func (s *UserService) GetUser(id uint) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	response := user.ToUserResponse()
+	return &response, nil
+}
+
+// CheckUserInTenant fails with "user not found" when the user does not belong to the caller's organization
+func (s *UserService) CheckUserInTenant(ctx context.Context, id uint) error {
+	if !s.userRepo.ExistsInTenant(ctx, id) {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// CreateUser creates a new user on behalf of an admin. The user joins the admin's organization
+// unless another one is requested, which requires canManageOrganizations.
+func (s *UserService) CreateUser(ctx context.Context, req *model.CreateUserRequest, canManageOrganizations bool) (*model.UserResponse, error) {
+	tenantID := model.TenantFromContext(ctx)
+	if req.TenantID != nil && *req.TenantID != tenantID {
+		if !canManageOrganizations {
+			return nil, errors.New("insufficient permissions")
+		}
+		if _, err := s.organizationRepo.FindByID(*req.TenantID); err != nil {
+			return nil, errors.New("organization not found")
+		}
+		tenantID = *req.TenantID
+	}
+
+	if s.userRepo.CheckUsernameExists(req.Username) {
+		return nil, errors.New("username already exists")
+	}
+	if s.userRepo.CheckEmailExists(req.Email, 0) {
+		return nil, errors.New("email already exists")
+	}
+
+	password := req.Password
+	if req.IsServiceAccount {
+		// Service accounts never log in with a password, give them an unusable random one
+		randomPassword, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		password = randomPassword
+	} else if password == "" {
+		return nil, errors.New("password is required")
+	} else if err := config.ValidatePassword(password, req.Username); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := config.HashPassword(password)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create response
-	response := &model.LoginResponse{
-		Token: token,
-		User:  user.ToUserResponse(),
+	roleNames := req.Roles
+	if len(roleNames) == 0 {
+		roleNames = []string{model.RoleUser}
+	}
+	roles, err := s.resolveRoles(roleNames, canManageOrganizations)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Username:           req.Username,
+		Email:              req.Email,
+		Password:           hashedPassword,
+		FullName:           req.FullName,
+		Roles:              roles,
+		TenantID:           tenantID,
+		IsActive:           true,
+		MustChangePassword: !req.IsServiceAccount, // the admin chose this password, so the user has to replace it on first login
+		IsServiceAccount:   req.IsServiceAccount,
+	}
+
+	if err := s.userRepo.CreateUser(user); err != nil {
+		return nil, handleDuplicateUserError(err)
+	}
+
+	response := user.ToUserResponse()
+	return &response, nil
+}
+
+// UpdateUser updates the provided fields of a user, including the roles. Users holding organizations:manage
+// can only be changed by callers that hold it too. All checks run before anything is written.
+func (s *UserService) UpdateUser(ctx context.Context, id uint, req *model.UpdateUserRequest, canManageOrganizations bool) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Changing the email address of such a user would allow taking the account over, and taking
+	// organizations:manage away is as sensitive as granting it
+	if !canManageOrganizations && slices.Contains(user.EffectivePermissions(), model.PermissionOrganizationsManage) {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	var roles []model.Role
+	if req.Roles != nil {
+		roles, err = s.resolveRoles(*req.Roles, canManageOrganizations)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var columns []string
+	if req.Email != nil {
+		if s.userRepo.CheckEmailExists(*req.Email, user.ID) {
+			return nil, errors.New("email already exists")
+		}
+		user.Email = *req.Email
+		columns = append(columns, "email")
+	}
+
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+		columns = append(columns, "full_name")
+	}
+
+	if err := s.userRepo.UpdateUser(user, columns...); err != nil {
+		return nil, handleDuplicateUserError(err)
+	}
+
+	if req.Roles != nil {
+		previousRoles := user.RoleNames()
+		if err := s.userRepo.ReplaceUserRoles(user, roles); err != nil {
+			return nil, err
+		}
+		user.Roles = roles
+		s.securityEvents.RecordRoleChange(ctx, user, previousRoles)
+
+		// Tokens carry the roles and permissions they were issued with
+		if err := s.tokenService.InvalidateAccessTokens(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	response := user.ToUserResponse()
+	return &response, nil
+}
+
+// SetUserActive deactivates or reactivates a user. Admins cannot deactivate themselves.
+func (s *UserService) SetUserActive(id uint, active bool, actingUserID uint) (*model.UserResponse, error) {
+	if !active && id == actingUserID {
+		return nil, errors.New("cannot deactivate your own account")
+	}
+
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	user.IsActive = active
+	columns := []string{"is_active"}
+	if active {
+		// Activating a self-registered user also completes its registration
+		user.RegistrationStatus = ""
+		columns = append(columns, "registration_status")
+	}
+	if err := s.userRepo.UpdateUser(user, columns...); err != nil {
+		return nil, err
+	}
+
+	// A deactivated user is signed out everywhere right away
+	if !active {
+		if err := s.tokenService.RevokeAllUserSessions(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	response := user.ToUserResponse()
+	return &response, nil
+}
+
+// DeleteUser soft-deletes a user. Admins cannot delete themselves.
+func (s *UserService) DeleteUser(id uint, actingUserID uint) error {
+	if id == actingUserID {
+		return errors.New("cannot delete your own account")
+	}
+
+	if _, err := s.userRepo.GetUserByID(id); err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := s.userRepo.DeleteUser(id); err != nil {
+		return err
+	}
+	return s.tokenService.RevokeAllUserSessions(id)
+}
+
+// UnlockUser lifts a temporary login lockout of a user
+func (s *UserService) UnlockUser(id uint) error {
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	return s.throttleService.Unlock(user.Username)
+}
+
+// resolveRoles loads roles by name and fails if any of them does not exist.
+// Roles granting organizations:manage are only assignable by callers that hold it.
+func (s *UserService) resolveRoles(names []string, canManageOrganizations bool) ([]model.Role, error) {
+	roles, err := s.roleRepo.FindByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(roles, func(r model.Role) bool { return r.Name == name }) {
+			return nil, errors.New("role not found")
+		}
+	}
+	if !canManageOrganizations && slices.ContainsFunc(roles, func(r model.Role) bool { return grantsOrganizationsManage(r.Permissions) }) {
+		return nil, errors.New("insufficient permissions")
+	}
+	return roles, nil
+}
+
+// inactiveAccountError explains why an inactive user cannot log in
+func inactiveAccountError(user *model.User) error {
+	switch user.RegistrationStatus {
+	case model.RegistrationPendingVerification:
+		return errors.New("email address has not been verified")
+	case model.RegistrationPendingApproval:
+		return errors.New("account is awaiting approval")
+	default:
+		return errors.New("account is deactivated")
+	}
+}
+
+// handleDuplicateUserError translates a unique index violation into the validation error of the field.
+// The existence checks before saving cannot rule it out when two requests race for the same name.
+func handleDuplicateUserError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Check for SQL Server unique constraint violation; the message names the index
+	if strings.Contains(err.Error(), "duplicate key") ||
+		strings.Contains(err.Error(), "UNIQUE constraint") ||
+		strings.Contains(err.Error(), "Cannot insert duplicate key") {
+		if strings.Contains(err.Error(), "idx_users_email") {
+			return errors.New("email already exists")
+		}
+		if strings.Contains(err.Error(), "idx_users_username") {
+			return errors.New("username already exists")
+		}
 	}
 
-	return response, nil
+	return err
 }
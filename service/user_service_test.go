@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go-gin-gorm-backend/config"
+	"go-gin-gorm-backend/model"
+	"go-gin-gorm-backend/repository"
+
+	"gorm.io/gorm"
+)
+
+func TestCheckUserInTenant(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		userID  uint
+		wantErr string
+	}{
+		{
+			name:   "user of the caller's organization",
+			ctx:    model.WithPrincipal(context.Background(), model.Principal{UserID: 2, Username: "admin", TenantID: 1}),
+			userID: 42,
+		},
+		{
+			name:    "user of another organization",
+			ctx:     model.WithPrincipal(context.Background(), model.Principal{UserID: 3, Username: "other_admin", TenantID: 2}),
+			userID:  42,
+			wantErr: "user not found",
+		},
+		{
+			name:    "no principal",
+			ctx:     context.Background(),
+			userID:  42,
+			wantErr: "user not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+
+			// Play the database holding user 42 in organization 1
+			onQuery(t, db, func(tx *gorm.DB) {
+				count, ok := tx.Statement.Dest.(*int64)
+				if ok && slices.Contains(tx.Statement.Vars, interface{}(uint(42))) && slices.Contains(tx.Statement.Vars, interface{}(uint(1))) {
+					*count = 1
+					tx.RowsAffected = 1
+				}
+			})
+
+			s := NewUserService(repository.NewUserRepository(db), nil, nil, nil, nil, nil, nil)
+			err := s.CheckUserInTenant(tt.ctx, tt.userID)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("CheckUserInTenant() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("CheckUserInTenant() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoginUserRevealsStatusOnlyWithPassword(t *testing.T) {
+	hash, err := config.HashPassword("correct-horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		status   string
+		password string
+		wantErr  string
+	}{
+		{name: "deactivated, wrong password", status: "", password: "guess", wantErr: "invalid credentials"},
+		{name: "unverified email, wrong password", status: model.RegistrationPendingVerification, password: "guess", wantErr: "invalid credentials"},
+		{name: "awaiting approval, wrong password", status: model.RegistrationPendingApproval, password: "guess", wantErr: "invalid credentials"},
+		{name: "deactivated, right password", status: "", password: "correct-horse", wantErr: "account is deactivated"},
+		{name: "unverified email, right password", status: model.RegistrationPendingVerification, password: "correct-horse", wantErr: "email address has not been verified"},
+		{name: "awaiting approval, right password", status: model.RegistrationPendingApproval, password: "correct-horse", wantErr: "account is awaiting approval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			onQuery(t, db, func(tx *gorm.DB) {
+				if user, ok := tx.Statement.Dest.(*model.User); ok {
+					*user = model.User{ID: 2, Username: "somchai", Password: hash, RegistrationStatus: tt.status}
+				}
+			})
+
+			throttleRepo := &fakeLoginThrottleRepository{throttles: make(map[string]*model.LoginThrottle)}
+			throttleService := NewLoginThrottleService(throttleRepo, &config.LoginThrottleConfig{MaxAttemptsPerUser: 5, MaxAttemptsPerIP: 20, AttemptWindow: time.Hour})
+			s := NewUserService(repository.NewUserRepository(db), nil, nil, nil, throttleService, nil, NewSecurityEventService(&fakeSecurityEventRepository{}))
+
+			_, err := s.LoginUser(&model.LoginRequest{Username: "somchai", Password: tt.password}, model.ClientInfo{IPAddress: "203.0.113.10"})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("LoginUser() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}